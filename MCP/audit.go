@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/audit"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/models"
+)
+
+// withAudit writes a record of every call of a mutating tool to cfg.Audit,
+// once the call has finished.
+func withAudit(cfg *config.APIConfig, rt registryTool, tool models.Tool) models.Tool {
+	if cfg.Audit == nil || rt.readOnly() {
+		return tool
+	}
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, _ := request.Params.Arguments.(map[string]any)
+		_, named := targetName(rt, args)
+		record := audit.Record{
+			Time:      time.Now().UTC(),
+			Tool:      tool.Definition.Name,
+			Resource:  targetString(rt, args),
+			Arguments: audit.Redact(args),
+			DryRun:    isDryRun(cfg, args),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			record.SessionID = session.SessionID()
+			if withInfo, ok := session.(server.SessionWithClientInfo); ok {
+				if info := withInfo.GetClientInfo(); info.Name != "" {
+					record.Client = &audit.Client{Name: info.Name, Version: info.Version}
+				}
+			}
+		}
+
+		ctx, trace := client.WithTrace(ctx)
+		result, err := handler(ctx, request)
+
+		// The change is the last request that is not a read, e.g. after the
+		// GETs counting what a delete removes.
+		var sent *client.Call
+		for _, call := range trace.Calls() {
+			if call.Method != http.MethodGet {
+				sent = &call
+			}
+		}
+		switch {
+		case sent != nil:
+			record.Method, record.Status, record.Attempts = sent.Method, sent.Status, sent.Attempts
+			record.LatencyMS = float64(sent.Latency.Microseconds()) / 1000
+			record.Outcome = audit.OutcomeOK
+			if sent.Err != nil {
+				record.Outcome, record.Error = audit.OutcomeFailed, sent.Err.Error()
+			}
+		case err != nil || (result != nil && result.IsError):
+			record.Outcome = audit.OutcomeRejected
+		default:
+			record.Outcome = audit.OutcomeNotSent
+		}
+		if err != nil {
+			record.Error = err.Error()
+		} else if result != nil && result.IsError && record.Error == "" {
+			record.Error = resultText(result)
+		}
+		if !named && record.Outcome == audit.OutcomeOK && record.Error == "" {
+			// The registry assigned the ID; its response names the resource.
+			var created struct{ Name string }
+			if json.Unmarshal([]byte(resultText(result)), &created) == nil && created.Name != "" {
+				record.Resource = created.Name
+			}
+		}
+
+		if werr := cfg.Audit.Write(record); werr != nil {
+			log.Printf("Failed to write audit record for %s: %v", record.Tool, werr)
+		}
+		return result, err
+	}
+	return tool
+}
+
+// resultText returns the text content of result.
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range result.Content {
+		if text, ok := c.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,145 @@
+// Package audit records the tool calls that can change the registry, as an
+// append-only trail of who changed what.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Destinations accepted by Open besides a file path.
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
+
+// Outcomes of an audited call.
+const (
+	OutcomeOK       = "ok"       // The upstream request succeeded
+	OutcomeFailed   = "failed"   // The upstream request was sent and failed
+	OutcomeRejected = "rejected" // The call failed before anything was sent
+	OutcomeNotSent  = "not_sent" // Nothing was sent, e.g. a dry run or a call awaiting confirmation
+)
+
+// Record describes one call of a mutating tool.
+type Record struct {
+	Time      time.Time      `json:"time"`
+	SessionID string         `json:"sessionId,omitempty"`
+	Client    *Client        `json:"client,omitempty"`
+	Tool      string         `json:"tool"`
+	Resource  string         `json:"resource,omitempty"` // Resolved name of the resource changed
+	Arguments map[string]any `json:"arguments"`          // Redacted, see Redact
+	DryRun    bool           `json:"dryRun,omitempty"`
+	Outcome   string         `json:"outcome"`
+	Method    string         `json:"method,omitempty"`    // Upstream HTTP method
+	Status    int            `json:"status,omitempty"`    // Upstream status code; 0 if no response was received
+	LatencyMS float64        `json:"latencyMs,omitempty"` // Upstream latency including retries
+	Attempts  int            `json:"attempts,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// Client identifies the MCP client that made a call, as declared when it
+// initialised the session.
+type Client struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Sink receives audit records. Implementations must be safe for concurrent
+// use.
+type Sink interface {
+	Write(Record) error
+}
+
+// JSONLines is a Sink writing each record as one line of JSON.
+type JSONLines struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLines returns a Sink writing to w.
+func NewJSONLines(w io.Writer) *JSONLines {
+	return &JSONLines{w: w}
+}
+
+func (j *JSONLines) Write(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.w.Write(data)
+	return err
+}
+
+// Open returns a JSON lines sink for dest: "stdout", "stderr" or the path of
+// a file, which is created if needed and only ever appended to. An empty dest
+// disables auditing and returns a nil Sink.
+func Open(dest string) (Sink, error) {
+	switch dest {
+	case "":
+		return nil, nil
+	case Stdout:
+		return NewJSONLines(os.Stdout), nil
+	case Stderr:
+		return NewJSONLines(os.Stderr), nil
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	return NewJSONLines(f), nil
+}
+
+// maxValue is the longest string argument recorded in full.
+const maxValue = 1024
+
+// Redact returns a copy of args fit for the audit trail: values of keys that
+// look like credentials are replaced, and long strings such as spec contents
+// are reduced to their length.
+func Redact(args map[string]any) map[string]any {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		out[k] = redactValue(k, v)
+	}
+	return out
+}
+
+func redactValue(key string, v any) any {
+	if secret(key) {
+		return "REDACTED"
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		return Redact(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = redactValue(key, e)
+		}
+		return out
+	case string:
+		if len(v) > maxValue {
+			return fmt.Sprintf("<%d bytes>", len(v))
+		}
+	}
+	return v
+}
+
+// secret reports whether an argument named key may hold a credential.
+func secret(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range []string{"token", "secret", "password", "credential", "apikey", "api_key", "confirm"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
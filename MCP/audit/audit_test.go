@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLines(&buf)
+	records := []Record{
+		{
+			Time:      time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+			SessionID: "stdio",
+			Client:    &Client{Name: "cursor", Version: "1.0"},
+			Tool:      "delete_api",
+			Resource:  "projects/p/locations/global/apis/a",
+			Arguments: map[string]any{"api": "a"},
+			Outcome:   OutcomeOK,
+			Method:    "DELETE",
+			Status:    200,
+			LatencyMS: 84.2,
+			Attempts:  1,
+		},
+		{
+			Time:      time.Date(2026, 1, 1, 12, 0, 1, 0, time.UTC),
+			Tool:      "create_api",
+			Arguments: map[string]any{},
+			DryRun:    true,
+			Outcome:   OutcomeNotSent,
+		},
+	}
+	for _, r := range records {
+		if err := sink.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		`{"time":"2026-01-01T12:00:00Z","sessionId":"stdio","client":{"name":"cursor","version":"1.0"},"tool":"delete_api","resource":"projects/p/locations/global/apis/a","arguments":{"api":"a"},"outcome":"ok","method":"DELETE","status":200,"latencyMs":84.2,"attempts":1}`,
+		`{"time":"2026-01-01T12:00:01Z","tool":"create_api","arguments":{},"dryRun":true,"outcome":"not_sent"}`,
+	}
+	if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("lines =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRedact(t *testing.T) {
+	long := strings.Repeat("x", maxValue+1)
+	args := map[string]any{
+		"api":         "petstore",
+		"confirm":     "1767268800.abc",
+		"accessToken": "secret",
+		"labels":      map[string]any{"team": "pets", "Password": "hunter2"},
+		"apiKeys":     []any{"k1", "k2"},
+		"contents":    long,
+		"force":       true,
+	}
+	want := map[string]any{
+		"api":         "petstore",
+		"confirm":     "REDACTED",
+		"accessToken": "REDACTED",
+		"labels":      map[string]any{"team": "pets", "Password": "REDACTED"},
+		"apiKeys":     "REDACTED",
+		"contents":    "<1025 bytes>",
+		"force":       true,
+	}
+	if got := Redact(args); !reflect.DeepEqual(got, want) {
+		t.Errorf("Redact() = %v, want %v", got, want)
+	}
+	if args["confirm"] != "1767268800.abc" || args["contents"] != long {
+		t.Error("Redact() changed its argument")
+	}
+}
+
+func TestOpenAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for _, tool := range []string{"create_api", "delete_api"} {
+		sink, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Write(Record{Tool: tool, Outcome: OutcomeOK}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tools []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		tools = append(tools, r.Tool)
+	}
+	if !reflect.DeepEqual(tools, []string{"create_api", "delete_api"}) {
+		t.Errorf("audit log has records of %v, want both calls", tools)
+	}
+
+	if sink, err := Open(""); sink != nil || err != nil {
+		t.Errorf("Open(\"\") = %v, %v, want no sink", sink, err)
+	}
+}
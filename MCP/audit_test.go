@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/registry-api/mcp-server/audit"
+)
+
+// auditServer serves a fresh fake registry with auditing to sink.
+func auditServer(t *testing.T, sink audit.Sink) *client.Client {
+	t.Helper()
+	up := &upstream{}
+	up.reset()
+	srv := httptest.NewServer(up)
+	t.Cleanup(srv.Close)
+	cfg := testConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Audit = sink
+	return serveStdio(t, cfg)
+}
+
+const demoName = "projects/demo/locations/global"
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		args     map[string]any
+		outcome  string
+		resource string // Prefix of the recorded resource
+		method   string
+		status   int
+		error    string // Substring of the recorded error, "" for none
+	}{
+		{"ok", "create_api", demo(map[string]any{"apiId": "orders", "labels": map[string]any{"apiToken": "secret"}}), audit.OutcomeOK, demoName + "/apis/orders", "POST", 200, ""},
+		{"ok without ID", "create_version", demo(map[string]any{"api": "petstore"}), audit.OutcomeOK, demoName + "/apis/petstore/versions/", "POST", 200, ""},
+		{"failed", "delete_api", demo(map[string]any{"api": "petstore"}), audit.OutcomeFailed, demoName + "/apis/petstore", "DELETE", 400, "FAILED_PRECONDITION"},
+		{"failed without ID", "create_version", demo(map[string]any{"api": "missing"}), audit.OutcomeFailed, demoName + "/apis/missing/versions", "POST", 404, "NOT_FOUND"},
+		{"rejected", "create_api", demo(map[string]any{"apiId": "-orders"}), audit.OutcomeRejected, demoName + "/apis/-orders", "", 0, "invalid api ID"},
+		{"dry run", "delete_api", demo(map[string]any{"api": "petstore", "dryRun": true}), audit.OutcomeNotSent, demoName + "/apis/petstore", "", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := auditServer(t, audit.NewJSONLines(&buf))
+			callTool(t, c, tt.tool, tt.args, nil)
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if len(lines) != 1 {
+				t.Fatalf("audit log = %q, want one record", buf.String())
+			}
+			var r audit.Record
+			if err := json.Unmarshal([]byte(lines[0]), &r); err != nil {
+				t.Fatalf("audit record %q: %v", lines[0], err)
+			}
+			if r.Time.IsZero() || r.SessionID == "" || r.Client == nil || r.Client.Name != "conformance" {
+				t.Errorf("record %s lacks the time, session or client", lines[0])
+			}
+			if r.Tool != tt.tool || !strings.HasPrefix(r.Resource, tt.resource) {
+				t.Errorf("record tool %q and resource %q, want %s of %s...", r.Tool, r.Resource, tt.tool, tt.resource)
+			}
+			if r.Outcome != tt.outcome || r.Method != tt.method || r.Status != tt.status {
+				t.Errorf("record outcome %q, method %q, status %d, want %q, %q, %d", r.Outcome, r.Method, r.Status, tt.outcome, tt.method, tt.status)
+			}
+			if tt.method != "" && (r.Attempts != 1 || r.LatencyMS <= 0) {
+				t.Errorf("record attempts %d and latency %vms, want 1 and some", r.Attempts, r.LatencyMS)
+			}
+			if tt.error == "" && r.Error != "" || !strings.Contains(r.Error, tt.error) {
+				t.Errorf("record error %q, want %q", r.Error, tt.error)
+			}
+			if _, dryRun := tt.args["dryRun"]; r.DryRun != dryRun {
+				t.Errorf("record dryRun = %v, want %v", r.DryRun, dryRun)
+			}
+			if strings.Contains(lines[0], "secret") {
+				t.Errorf("record %s contains a secret argument", lines[0])
+			}
+		})
+	}
+}
+
+func TestAuditSkipsReads(t *testing.T) {
+	var buf bytes.Buffer
+	c := auditServer(t, audit.NewJSONLines(&buf))
+	if res := callTool(t, c, "get_api", demo(map[string]any{"api": "petstore"}), nil); res.IsError {
+		t.Fatalf("get_api failed: %q", text(res))
+	}
+	if buf.Len() != 0 {
+		t.Errorf("audit log = %q, want no record of a read", buf.String())
+	}
+}
+
+// failingSink rejects every record.
+type failingSink struct{ writes int }
+
+func (s *failingSink) Write(audit.Record) error {
+	s.writes++
+	return errors.New("disk full")
+}
+
+func TestAuditSinkFailure(t *testing.T) {
+	sink := &failingSink{}
+	c := auditServer(t, sink)
+	res := callTool(t, c, "create_api", demo(map[string]any{"apiId": "orders"}), nil)
+	if res.IsError {
+		t.Fatalf("create_api failed with a failing audit sink: %q", text(res))
+	}
+	if got := object(t, res)["name"]; got != "projects/demo/locations/global/apis/orders" {
+		t.Errorf("create_api result name = %v, want the new API", got)
+	}
+	if sink.writes != 1 {
+		t.Errorf("sink written %d times, want 1", sink.writes)
+	}
+}
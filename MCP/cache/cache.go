@@ -0,0 +1,145 @@
+// Package cache keeps registry responses for a while so that repeated reads
+// within a conversation are answered without a round trip. Entries expire
+// after a TTL and the least recently used ones are evicted when the cache is
+// full; expired entries are kept until then so they can be revalidated.
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Body     []byte
+	Header   http.Header
+	Resource string // The resource or collection the response describes, for Invalidate
+	Listing  bool   // Whether the response lists a collection
+	Hash     string // For contents, the hex SHA-256 of the uncompressed payload, which revalidates the entry once it expires
+	Stored   time.Time
+}
+
+// Stats counts cache activity since the cache was created.
+type Stats struct {
+	Hits          int64 `json:"hits"`          // Reads answered from the cache, including revalidated entries
+	Misses        int64 `json:"misses"`        // Reads sent upstream
+	Revalidations int64 `json:"revalidations"` // Expired entries found unchanged upstream
+	Invalidations int64 `json:"invalidations"` // Entries dropped because of a change
+	Evictions     int64 `json:"evictions"`     // Entries dropped to make room
+	Entries       int   `json:"entries"`
+}
+
+// Cache is an LRU cache of responses with a TTL. It is safe for concurrent
+// use.
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu    sync.Mutex
+	order *list.List               // Most recently used first
+	items map[string]*list.Element // Values are *item
+
+	hits, misses, revalidations, invalidations, evictions atomic.Int64
+}
+
+type item struct {
+	key   string
+	entry Entry
+}
+
+// New returns a cache whose entries are fresh for ttl and which holds at most
+// maxEntries responses.
+func New(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		order:      list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+// Get returns the entry stored under key and whether it is still fresh.
+func (c *Cache) Get(key string) (e Entry, fresh, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return Entry{}, false, false
+	}
+	c.order.MoveToFront(el)
+	e = el.Value.(*item).entry
+	return e, c.now().Sub(e.Stored) < c.ttl, true
+}
+
+// Put stores e under key, evicting the least recently used entries if the
+// cache is full.
+func (c *Cache) Put(key string, e Entry) {
+	e.Stored = c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*item).entry = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&item{key: key, entry: e})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*item).key)
+		c.evictions.Add(1)
+	}
+}
+
+// Touch marks the entry under key as fresh again after it was revalidated.
+func (c *Cache) Touch(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*item).entry.Stored = c.now()
+		c.revalidations.Add(1)
+	}
+}
+
+// Invalidate drops the entries matching match and returns how many there
+// were.
+func (c *Cache) Invalidate(match func(Entry) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if it := el.Value.(*item); match(it.entry) {
+			c.order.Remove(el)
+			delete(c.items, it.key)
+			n++
+		}
+		el = next
+	}
+	c.invalidations.Add(int64(n))
+	return n
+}
+
+// Hit and Miss count a read answered from the cache or sent upstream.
+func (c *Cache) Hit()  { c.hits.Add(1) }
+func (c *Cache) Miss() { c.misses.Add(1) }
+
+// Stats returns the cache's counters.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+	return Stats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Revalidations: c.revalidations.Load(),
+		Invalidations: c.invalidations.Load(),
+		Evictions:     c.evictions.Load(),
+		Entries:       entries,
+	}
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestCache returns a cache with a clock that only moves when the
+// returned function is called.
+func newTestCache(ttl time.Duration, maxEntries int) (*Cache, func(time.Duration)) {
+	c := New(ttl, maxEntries)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestTTL(t *testing.T) {
+	c, advance := newTestCache(time.Minute, 10)
+	c.Put("a", Entry{Body: []byte("A")})
+
+	advance(59 * time.Second)
+	if e, fresh, ok := c.Get("a"); !ok || !fresh || string(e.Body) != "A" {
+		t.Errorf("Get before the TTL = %q, fresh %v, ok %v; want A, fresh", e.Body, fresh, ok)
+	}
+
+	// Expired entries are kept for revalidation.
+	advance(time.Second)
+	if e, fresh, ok := c.Get("a"); !ok || fresh || string(e.Body) != "A" {
+		t.Errorf("Get at the TTL = %q, fresh %v, ok %v; want A, expired", e.Body, fresh, ok)
+	}
+
+	c.Touch("a")
+	if _, fresh, _ := c.Get("a"); !fresh {
+		t.Error("entry still expired after Touch")
+	}
+	advance(30 * time.Second)
+	c.Put("a", Entry{Body: []byte("A2")})
+	advance(45 * time.Second)
+	if e, fresh, _ := c.Get("a"); !fresh || string(e.Body) != "A2" {
+		t.Errorf("Get after Put replaced the entry = %q, fresh %v; want A2, fresh", e.Body, fresh)
+	}
+	if s := c.Stats(); s.Revalidations != 1 || s.Entries != 1 {
+		t.Errorf("Stats() = %+v, want 1 revalidation and 1 entry", s)
+	}
+
+	if _, _, ok := c.Get("missing"); ok {
+		t.Error("Get of a missing key succeeded")
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 3)
+	for _, key := range []string{"a", "b", "c"} {
+		c.Put(key, Entry{})
+	}
+	c.Get("a") // b is now the least recently used
+	c.Put("d", Entry{})
+	c.Put("b", Entry{}) // Evicts c
+	c.Put("a", Entry{}) // Already present: nothing evicted
+
+	var kept []string
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if _, _, ok := c.Get(key); ok {
+			kept = append(kept, key)
+		}
+	}
+	if got := strings.Join(kept, ","); got != "a,b,d" {
+		t.Errorf("kept %s, want a,b,d", got)
+	}
+	if s := c.Stats(); s.Evictions != 2 || s.Entries != 3 {
+		t.Errorf("Stats() = %+v, want 2 evictions and 3 entries", s)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 10)
+	c.Put("api", Entry{Resource: "r/apis/a"})
+	c.Put("version", Entry{Resource: "r/apis/a/versions/v"})
+	c.Put("other", Entry{Resource: "r/apis/b"})
+	c.Put("list", Entry{Resource: "r/apis", Listing: true})
+
+	n := c.Invalidate(func(e Entry) bool {
+		return e.Listing || e.Resource == "r/apis/a" || strings.HasPrefix(e.Resource, "r/apis/a/")
+	})
+	if n != 3 {
+		t.Errorf("Invalidate() = %d, want 3", n)
+	}
+	for key, want := range map[string]bool{"api": false, "version": false, "list": false, "other": true} {
+		if _, _, ok := c.Get(key); ok != want {
+			t.Errorf("%s cached = %v after Invalidate, want %v", key, ok, want)
+		}
+	}
+
+	c.Hit()
+	c.Miss()
+	c.Miss()
+	want := Stats{Hits: 1, Misses: 2, Invalidations: 3, Entries: 1}
+	if s := c.Stats(); s != want {
+		t.Errorf("Stats() = %+v, want %+v", s, want)
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+func locationPath(project, location string) path {
+	return path{}.child(names.Projects, project).child(names.Locations, location)
+}
+
+func apiPath(project, location, api string) path {
+	return locationPath(project, location).child(names.Apis, api)
+}
+
+// ListApis returns matching APIs.
+func (c *Client) ListApis(ctx context.Context, project, location string, opts *ListOptions) (*models.ListApisResponse, error) {
+	var out models.ListApisResponse
+	if err := c.do(ctx, "GET", locationPath(project, location).collection(names.Apis), opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetApi returns a specified API.
+func (c *Client) GetApi(ctx context.Context, project, location, api string) (*models.Api, error) {
+	var out models.Api
+	if err := c.do(ctx, "GET", apiPath(project, location, api), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateApi creates a specified API. apiID becomes the final component of the
+// API's resource name.
+func (c *Client) CreateApi(ctx context.Context, project, location, apiID string, body *models.Api) (*models.Api, error) {
+	var out models.Api
+	query, err := idValues(names.Apis, "apiId", apiID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, "POST", locationPath(project, location).collection(names.Apis), query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateApi can be used to modify a specified API.
+func (c *Client) UpdateApi(ctx context.Context, project, location, api string, body *models.Api, opts *UpdateOptions) (*models.Api, error) {
+	var out models.Api
+	if err := c.do(ctx, "PATCH", apiPath(project, location, api), opts.values(), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteApi removes a specified API and all of the resources that it owns.
+// Without force the request fails if the API has child resources.
+func (c *Client) DeleteApi(ctx context.Context, project, location, api string, force bool) error {
+	return c.do(ctx, "DELETE", apiPath(project, location, api), forceValues(force), nil, nil)
+}
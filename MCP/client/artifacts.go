@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+func artifactPath(project, location, artifact string) path {
+	return locationPath(project, location).child(names.Artifacts, artifact)
+}
+
+// ListArtifacts returns matching artifacts.
+func (c *Client) ListArtifacts(ctx context.Context, project, location string, opts *ListOptions) (*models.ListArtifactsResponse, error) {
+	var out models.ListArtifactsResponse
+	if err := c.do(ctx, "GET", locationPath(project, location).collection(names.Artifacts), opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetArtifact returns a specified artifact.
+func (c *Client) GetArtifact(ctx context.Context, project, location, artifact string) (*models.Artifact, error) {
+	var out models.Artifact
+	if err := c.do(ctx, "GET", artifactPath(project, location, artifact), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetArtifactContents returns the contents of a specified artifact.
+func (c *Client) GetArtifactContents(ctx context.Context, project, location, artifact string) (*HttpBody, error) {
+	return c.raw(ctx, artifactPath(project, location, artifact).withVerb("getContents"))
+}
+
+// CreateArtifact creates a specified artifact.
+func (c *Client) CreateArtifact(ctx context.Context, project, location, artifactID string, body *models.Artifact) (*models.Artifact, error) {
+	var out models.Artifact
+	query, err := idValues(names.Artifacts, "artifactId", artifactID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, "POST", locationPath(project, location).collection(names.Artifacts), query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReplaceArtifact can be used to replace a specified artifact.
+func (c *Client) ReplaceArtifact(ctx context.Context, project, location, artifact string, body *models.Artifact) (*models.Artifact, error) {
+	var out models.Artifact
+	if err := c.do(ctx, "PUT", artifactPath(project, location, artifact), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteArtifact removes a specified artifact.
+func (c *Client) DeleteArtifact(ctx context.Context, project, location, artifact string) error {
+	return c.do(ctx, "DELETE", artifactPath(project, location, artifact), nil, nil, nil)
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/registry-api/mcp-server/cache"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+// WithCache replaces the response cache taken from the configuration; nil
+// disables caching, e.g. for clients that poll for changes.
+func WithCache(rc *cache.Cache) Option {
+	return func(c *Client) {
+		c.cache = rc
+	}
+}
+
+// cacheKey identifies a read in the cache. The same URL read with other
+// credentials is cached separately, so nobody is answered with what only
+// someone else may read; credentials are hashed rather than kept in keys.
+func cacheKey(cfg *config.APIConfig, target, accept string) string {
+	h := sha256.New()
+	for _, s := range []string{cfg.BearerToken, cfg.BasicAuth, cfg.APIKey, cfg.APIKeyName, cfg.APIKeyIn} {
+		io.WriteString(h, s)
+		h.Write([]byte{0})
+	}
+	if cfg.BearerToken == "" && cfg.TokenSource != nil {
+		fmt.Fprintf(h, "%p", cfg.TokenSource)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]) + " " + accept + " " + target
+}
+
+// scope returns the resource or collection p refers to below the registry at
+// baseURL, without any revision or custom verb, e.g.
+// "https://registry/projects/p/locations/l/apis/a/versions/v/specs/s".
+func scope(baseURL string, p path) string {
+	segments := slices.Clone(p.segments)
+	if n := len(segments); n%2 == 0 {
+		segments[n-1], _, _ = strings.Cut(segments[n-1], "@")
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.Join(segments, "/")
+}
+
+// cached returns the response to a read from the cache, revalidating expired
+// contents against the hash of their spec or artifact. It counts a hit or a
+// miss.
+func (c *Client) cached(ctx context.Context, key string, p path) ([]byte, http.Header, bool) {
+	e, fresh, ok := c.cache.Get(key)
+	if ok && !fresh && e.Hash != "" && c.unchanged(ctx, p, e.Hash) {
+		c.cache.Touch(key)
+		fresh = true
+	}
+	if !ok || !fresh {
+		c.cache.Miss()
+		return nil, nil, false
+	}
+	c.cache.Hit()
+	return slices.Clone(e.Body), e.Header.Clone(), true
+}
+
+// unchanged reports whether the spec or artifact whose contents p reads still
+// has contents with the given hash. The registry is asked directly, since a
+// cached copy of the metadata may be as stale as the contents.
+func (c *Client) unchanged(ctx context.Context, p path, hash string) bool {
+	p.verb = ""
+	direct := *c
+	direct.cache = nil
+	var current string
+	switch p.segments[len(p.segments)-2] {
+	case names.Specs:
+		var spec models.ApiSpec
+		if err := direct.do(ctx, "GET", p, nil, nil, &spec); err != nil {
+			return false
+		}
+		current = spec.Hash
+	case names.Artifacts:
+		var artifact models.Artifact
+		if err := direct.do(ctx, "GET", p, nil, nil, &artifact); err != nil {
+			return false
+		}
+		current = artifact.Hash
+	}
+	return current != "" && current == hash
+}
+
+// store caches a successful read of p.
+func (c *Client) store(key, baseURL string, p path, body []byte, header http.Header) {
+	e := cache.Entry{
+		Body:     slices.Clone(body),
+		Header:   header.Clone(),
+		Resource: scope(baseURL, p),
+		Listing:  len(p.segments)%2 == 1 || p.verb == "listRevisions",
+	}
+	if p.verb == "getContents" {
+		data, _, err := (&HttpBody{ContentType: header.Get("Content-Type"), Data: body}).Uncompressed()
+		if err == nil {
+			sum := sha256.Sum256(data)
+			e.Hash = hex.EncodeToString(sum[:])
+		}
+	}
+	c.cache.Put(key, e)
+}
+
+// invalidate drops what a change to p may have made stale, whoever read it:
+// the resource and everything below it, and every listing from the same
+// registry, since lists can be filtered on any field and span parents with
+// "-".
+func (c *Client) invalidate(baseURL string, p path) {
+	resource := scope(baseURL, p)
+	registry := strings.TrimSuffix(baseURL, "/") + "/"
+	created := len(p.segments)%2 == 1
+	c.cache.Invalidate(func(e cache.Entry) bool {
+		if e.Listing && strings.HasPrefix(e.Resource, registry) {
+			return true
+		}
+		return !created && (e.Resource == resource || strings.HasPrefix(e.Resource, resource+"/"))
+	})
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/registry-api/mcp-server/cache"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/fake"
+	"github.com/registry-api/mcp-server/models"
+)
+
+// newCached returns a client with a cache of the given TTL in front of a
+// seeded fake registry.
+func newCached(t *testing.T, ttl time.Duration) (*Client, *fake.Registry, *httptest.Server) {
+	t.Helper()
+	f := fake.New()
+	f.Seed("demo", "global")
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return New(&config.APIConfig{
+		BaseURL:     srv.URL,
+		BearerToken: "alice",
+		Timeout:     5 * time.Second,
+		Retry:       config.RetryPolicy{MaxAttempts: 1},
+		Cache:       cache.New(ttl, 100),
+	}), f, srv
+}
+
+func TestCacheKeyedByCredentials(t *testing.T) {
+	c, f, srv := newCached(t, time.Minute)
+	read := func(ctx context.Context) {
+		t.Helper()
+		if _, err := c.GetApi(ctx, "demo", "global", "petstore"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	read(ctx)
+	read(ctx)
+	if n := len(f.Requests()); n != 1 {
+		t.Fatalf("sent %d requests for two reads with the same credentials, want 1", n)
+	}
+
+	for _, cfg := range []*config.APIConfig{
+		{BaseURL: srv.URL, BearerToken: "bob"},
+		{BaseURL: srv.URL, APIKey: "alice"},
+		{BaseURL: srv.URL},
+	} {
+		read(config.NewContext(ctx, cfg))
+	}
+	if n := len(f.Requests()); n != 4 {
+		t.Errorf("sent %d requests, want each set of credentials to read upstream once", n)
+	}
+	for _, r := range f.Requests()[1:] {
+		if r.Header.Get("Authorization") == "Bearer alice" {
+			t.Error("a read with other credentials was sent with alice's token")
+		}
+	}
+}
+
+func TestCacheInvalidatedByWrites(t *testing.T) {
+	c, f, _ := newCached(t, time.Minute)
+	ctx := context.Background()
+	reads := func() {
+		t.Helper()
+		if _, err := c.GetApi(ctx, "demo", "global", "petstore"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.GetApi(ctx, "demo", "global", "payments"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.ListApis(ctx, "demo", "global", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reads()
+	reads()
+	if n := len(f.Requests()); n != 3 {
+		t.Fatalf("sent %d requests for repeated reads, want 3", n)
+	}
+
+	if _, err := c.UpdateApi(ctx, "demo", "global", "petstore", &models.Api{Description: "Pets."}, nil); err != nil {
+		t.Fatal(err)
+	}
+	reads()
+	var again []string
+	for _, r := range f.Requests()[4:] {
+		again = append(again, r.Path)
+	}
+	want := []string{"/v1/projects/demo/locations/global/apis/petstore", "/v1/projects/demo/locations/global/apis"}
+	if strings.Join(again, " ") != strings.Join(want, " ") {
+		t.Errorf("after the update reads sent %q, want the changed API and the listing", again)
+	}
+	if api, _ := c.GetApi(ctx, "demo", "global", "petstore"); api == nil || api.Description != "Pets." {
+		t.Errorf("cached API after the update = %+v, want the new description", api)
+	}
+}
+
+func TestCacheRevalidatesContents(t *testing.T) {
+	c, f, _ := newCached(t, 200*time.Millisecond)
+	ctx := context.Background()
+	for range 2 {
+		if _, err := c.GetApiSpecContents(ctx, "demo", "global", "petstore", "v1", "openapi"); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+	if _, err := c.GetApiSpecContents(ctx, "demo", "global", "petstore", "v1", "openapi"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The contents are read once; expired copies are revalidated against
+	// the spec's hash.
+	var paths []string
+	for _, r := range f.Requests() {
+		paths = append(paths, r.Path)
+	}
+	want := []string{demoSpecPath + ":getContents", demoSpecPath, demoSpecPath}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("sent %q, want %q", paths, want)
+	}
+	if s := c.cache.Stats(); s.Revalidations != 2 || s.Hits != 2 {
+		t.Errorf("cache stats = %+v, want 2 revalidations and 2 hits", s)
+	}
+}
+
+const demoSpecPath = "/v1/projects/demo/locations/global/apis/petstore/versions/v1/specs/openapi"
+
+func TestCacheRevalidationBypassesCache(t *testing.T) {
+	c, _, srv := newCached(t, time.Second)
+	ctx := context.Background()
+	if _, err := c.GetApiSpecContents(ctx, "demo", "global", "petstore", "v1", "openapi"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Half a TTL later the spec is read, and then changed behind the
+	// client's back, so its cached hash is stale while still fresh.
+	time.Sleep(600 * time.Millisecond)
+	if _, err := c.GetApiSpec(ctx, "demo", "global", "petstore", "v1", "openapi"); err != nil {
+		t.Fatal(err)
+	}
+	body := `{"contents": "` + base64.StdEncoding.EncodeToString([]byte("openapi: 3.1.0\n")) + `"}`
+	req, _ := http.NewRequest(http.MethodPatch, srv.URL+demoSpecPath, strings.NewReader(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	time.Sleep(600 * time.Millisecond)
+	contents, err := c.GetApiSpecContents(ctx, "demo", "global", "petstore", "v1", "openapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _, _ := contents.Uncompressed(); string(data) != "openapi: 3.1.0\n" {
+		t.Errorf("contents after the change = %.40q..., want the new contents", data)
+	}
+}
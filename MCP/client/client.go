@@ -0,0 +1,300 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/registry-api/mcp-server/cache"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/metrics"
+	"github.com/registry-api/mcp-server/names"
+	"github.com/registry-api/mcp-server/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Client is a typed client for the Registry API. It has one method per
+// registry operation; every method honours ctx for cancellation.
+type Client struct {
+	cfg   *config.APIConfig
+	http  *http.Client
+	cache *cache.Cache
+}
+
+// Option customises a Client.
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying HTTP client, e.g. to use a custom
+// transport. The client's own Timeout is used as-is.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// New returns a Client talking to cfg.BaseURL with the credentials in cfg.
+// Requests time out after cfg.Timeout unless ctx expires first. A
+// configuration carried by a request's ctx (see config.NewContext) takes
+// precedence over cfg for its base URL, credentials and retries. Reads are
+// cached in cfg.Cache, if set.
+func New(cfg *config.APIConfig, opts ...Option) *Client {
+	c := &Client{
+		cfg:   cfg,
+		http:  &http.Client{Timeout: cfg.Timeout},
+		cache: cfg.Cache,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is returned when the registry answers with a status code >= 400.
+// Status holds the decoded google.rpc.Status from the response body.
+type Error struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Status     Status
+}
+
+func (e *Error) Error() string {
+	if e.Status.Message == "" {
+		return fmt.Sprintf("%s (HTTP %d)", e.Status.Status, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s", e.Status.Status, e.Status.Message)
+}
+
+// HttpBody is the raw payload returned by the getContents operations, as
+// described by its Content-Type.
+type HttpBody struct {
+	ContentType string
+	Data        []byte
+}
+
+// ListOptions holds the paging and filtering parameters shared by the list
+// operations. Zero values are omitted from the request.
+type ListOptions struct {
+	PageSize  int
+	PageToken string
+	Filter    string
+}
+
+func (o *ListOptions) values() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+	if o.PageSize != 0 {
+		q.Set("pageSize", strconv.Itoa(o.PageSize))
+	}
+	if o.PageToken != "" {
+		q.Set("pageToken", o.PageToken)
+	}
+	if o.Filter != "" {
+		q.Set("filter", o.Filter)
+	}
+	return q
+}
+
+// UpdateOptions holds the parameters shared by the update operations.
+type UpdateOptions struct {
+	UpdateMask   string
+	AllowMissing bool
+}
+
+func (o *UpdateOptions) values() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+	if o.UpdateMask != "" {
+		q.Set("updateMask", o.UpdateMask)
+	}
+	if o.AllowMissing {
+		q.Set("allowMissing", "true")
+	}
+	return q
+}
+
+func forceValues(force bool) url.Values {
+	q := url.Values{}
+	if force {
+		q.Set("force", "true")
+	}
+	return q
+}
+
+// idValues sets the query parameter name to id, the ID of a new resource in
+// collection, after validating it.
+func idValues(collection, name, id string) (url.Values, error) {
+	q := url.Values{}
+	if id != "" {
+		if err := names.ValidateID(collection, id); err != nil {
+			return nil, err
+		}
+		q.Set(name, id)
+	}
+	return q, nil
+}
+
+// do sends a JSON request and decodes a JSON response into out (if non-nil).
+func (c *Client) do(ctx context.Context, method string, p path, query url.Values, in, out any) error {
+	body, _, err := c.send(ctx, method, p, query, in, "application/json")
+	if err != nil {
+		return err
+	}
+	if out == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// raw sends a GET request accepting any media type and returns the
+// undecoded response body.
+func (c *Client) raw(ctx context.Context, p path) (*HttpBody, error) {
+	body, header, err := c.send(ctx, "GET", p, nil, nil, "*/*")
+	if err != nil {
+		return nil, err
+	}
+	return &HttpBody{ContentType: header.Get("Content-Type"), Data: body}, nil
+}
+
+// send builds the request path, rejecting invalid IDs before anything is
+// sent, and performs the request with retries. In a dry run the request is
+// recorded instead; see WithDryRun. With a cache, reads are answered from it
+// when possible, and any other request invalidates what it may have changed.
+func (c *Client) send(ctx context.Context, method string, p path, query url.Values, in any, accept string) ([]byte, http.Header, error) {
+	path, err := p.build()
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := config.FromContext(ctx, c.cfg)
+	target := strings.TrimSuffix(cfg.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var data []byte
+	if in != nil {
+		if data, err = json.Marshal(in); err != nil {
+			return nil, nil, fmt.Errorf("encoding request body: %w", err)
+		}
+	}
+
+	if recorded, err := record(ctx, cfg, method, target, data, accept); recorded {
+		return nil, http.Header{}, err
+	}
+
+	key := ""
+	if c.cache != nil && method == http.MethodGet {
+		key = cacheKey(cfg, target, accept)
+		if body, header, ok := c.cached(ctx, key, p); ok {
+			trace.SpanFromContext(ctx).AddEvent("cache hit", trace.WithAttributes(attribute.String("url.full", target)))
+			return body, header, nil
+		}
+	}
+	body, header, err := c.roundTrip(ctx, cfg, method, path, p.route(), target, data, accept)
+	switch {
+	case c.cache == nil:
+	case key != "":
+		if err == nil {
+			c.store(key, cfg.BaseURL, p, body, header)
+		}
+	case method != http.MethodGet:
+		c.invalidate(cfg.BaseURL, p)
+	}
+	return body, header, err
+}
+
+// roundTrip performs a request, retrying transient failures of idempotent
+// requests as cfg.Retry allows. Every attempt is observed in the upstream
+// metrics under route and traced in a span of its own.
+func (c *Client) roundTrip(ctx context.Context, cfg *config.APIConfig, method, path, route, target string, data []byte, accept string) ([]byte, http.Header, error) {
+	retryable := isIdempotent(method, path)
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		attemptStart := time.Now()
+		actx, span := startSpan(ctx, method, route, target, attempt)
+		metrics.UpstreamInFlight.Inc()
+		body, header, status, err := c.attempt(actx, cfg, method, target, data, accept)
+		metrics.UpstreamInFlight.Dec()
+		metrics.UpstreamDuration.WithLabelValues(method, route, metrics.StatusCode(status)).Observe(time.Since(attemptStart).Seconds())
+		endSpan(span, status, err)
+		done := func() {
+			traceCall(ctx, Call{Method: method, Path: path, Status: status, Latency: time.Since(start), Attempts: attempt, Err: err})
+		}
+		if err == nil || !retryable || attempt >= cfg.Retry.MaxAttempts {
+			done()
+			return body, header, err
+		}
+		delay, ok := retryDelay(ctx, cfg.Retry, attempt, err)
+		if !ok {
+			done()
+			return nil, nil, err
+		}
+		log.Printf("Retrying %s %s in %v (attempt %d of %d): %v", method, path, delay, attempt+1, cfg.Retry.MaxAttempts, err)
+		metrics.UpstreamRetries.WithLabelValues(method, route).Inc()
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			done()
+			return nil, nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt performs a single round trip and returns the response status code,
+// or 0 if there was no response. The trace context of ctx is sent along. If
+// the registry rejects a token from the token source, the token is dropped
+// and the request is sent once more with a fresh one.
+func (c *Client) attempt(ctx context.Context, cfg *config.APIConfig, method, target string, data []byte, accept string) ([]byte, http.Header, int, error) {
+	for refreshed := false; ; refreshed = true {
+		var reqBody io.Reader
+		if data != nil {
+			reqBody = bytes.NewReader(data)
+		}
+		req, err := NewRequest(ctx, cfg, method, target, reqBody)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		req.Header.Set("Accept", accept)
+		telemetry.Propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, resp.StatusCode, fmt.Errorf("reading response body: %w", err)
+		}
+		if resp.StatusCode == http.StatusUnauthorized && !refreshed && dropToken(cfg, req) {
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return nil, nil, resp.StatusCode, &Error{
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				Body:       body,
+				Status:     decodeStatus(resp.StatusCode, body),
+			}
+		}
+		return body, resp.Header, resp.StatusCode, nil
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Gzipped reports whether the payload is gzip-compressed, as indicated by a
+// "+gzip" media type suffix. The registry usually decompresses contents
+// itself, so the payload's magic bytes are checked as well.
+func (b *HttpBody) Gzipped() bool {
+	return strings.Contains(b.ContentType, "+gzip") && bytes.HasPrefix(b.Data, gzipMagic)
+}
+
+// Uncompressed returns the payload with any gzip compression removed, along
+// with its media type minus the "+gzip" suffix.
+func (b *HttpBody) Uncompressed() ([]byte, string, error) {
+	mimeType := strings.Replace(b.ContentType, "+gzip", "", 1)
+	if !b.Gzipped() {
+		return b.Data, mimeType, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b.Data))
+	if err != nil {
+		return nil, "", fmt.Errorf("decompressing contents: %w", err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, "", fmt.Errorf("decompressing contents: %w", err)
+	}
+	return data, mimeType, nil
+}
+
+// IsText reports whether data of the given media type should be presented as
+// text. Archives and other declared binary types never are; anything else is
+// text when it is valid UTF-8 without NUL bytes, which covers YAML, JSON,
+// GraphQL and .proto sources whatever vendor type they are labelled with.
+func IsText(mimeType string, data []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = strings.ToLower(mimeType)
+	}
+	for _, marker := range []string{"zip", "octet-stream", "image/", "audio/", "video/", "x-protobuf", "x.protobuf"} {
+		if strings.Contains(mediaType, marker) {
+			return false
+		}
+	}
+	return utf8.Valid(data) && !bytes.ContainsRune(data, 0)
+}
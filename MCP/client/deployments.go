@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+func deploymentPath(project, location, api, deployment string) path {
+	return apiPath(project, location, api).child(names.Deployments, deployment)
+}
+
+// ListApiDeployments returns matching deployments.
+func (c *Client) ListApiDeployments(ctx context.Context, project, location, api string, opts *ListOptions) (*models.ListApiDeploymentsResponse, error) {
+	var out models.ListApiDeploymentsResponse
+	if err := c.do(ctx, "GET", apiPath(project, location, api).collection(names.Deployments), opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetApiDeployment returns a specified deployment.
+func (c *Client) GetApiDeployment(ctx context.Context, project, location, api, deployment string) (*models.ApiDeployment, error) {
+	var out models.ApiDeployment
+	if err := c.do(ctx, "GET", deploymentPath(project, location, api, deployment), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateApiDeployment creates a specified deployment.
+func (c *Client) CreateApiDeployment(ctx context.Context, project, location, api, deploymentID string, body *models.ApiDeployment) (*models.ApiDeployment, error) {
+	var out models.ApiDeployment
+	query, err := idValues(names.Deployments, "apiDeploymentId", deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, "POST", apiPath(project, location, api).collection(names.Deployments), query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateApiDeployment can be used to modify a specified deployment.
+func (c *Client) UpdateApiDeployment(ctx context.Context, project, location, api, deployment string, body *models.ApiDeployment, opts *UpdateOptions) (*models.ApiDeployment, error) {
+	var out models.ApiDeployment
+	if err := c.do(ctx, "PATCH", deploymentPath(project, location, api, deployment), opts.values(), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteApiDeployment removes a specified deployment, all revisions, and all
+// child resources (e.g. artifacts).
+func (c *Client) DeleteApiDeployment(ctx context.Context, project, location, api, deployment string, force bool) error {
+	return c.do(ctx, "DELETE", deploymentPath(project, location, api, deployment), forceValues(force), nil, nil)
+}
+
+// ListApiDeploymentRevisions lists all revisions of a deployment, most recent
+// first.
+func (c *Client) ListApiDeploymentRevisions(ctx context.Context, project, location, api, deployment string, opts *ListOptions) (*models.ListApiDeploymentRevisionsResponse, error) {
+	var out models.ListApiDeploymentRevisionsResponse
+	if err := c.do(ctx, "GET", deploymentPath(project, location, api, deployment).withVerb("listRevisions"), opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TagApiDeploymentRevision adds a tag to a specified revision of a deployment.
+func (c *Client) TagApiDeploymentRevision(ctx context.Context, project, location, api, deployment string, body *models.TagApiDeploymentRevisionRequest) (*models.ApiDeployment, error) {
+	var out models.ApiDeployment
+	if err := c.do(ctx, "POST", deploymentPath(project, location, api, deployment).withVerb("tagRevision"), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RollbackApiDeployment sets the current revision to a specified prior
+// revision. This creates a new revision with a new revision ID.
+func (c *Client) RollbackApiDeployment(ctx context.Context, project, location, api, deployment string, body *models.RollbackApiDeploymentRequest) (*models.ApiDeployment, error) {
+	var out models.ApiDeployment
+	if err := c.do(ctx, "POST", deploymentPath(project, location, api, deployment).withVerb("rollback"), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteApiDeploymentRevision deletes a revision of a deployment. deployment
+// must carry the revision, e.g. "prod@c7cfa2a8".
+func (c *Client) DeleteApiDeploymentRevision(ctx context.Context, project, location, api, deployment string) (*models.ApiDeployment, error) {
+	var out models.ApiDeployment
+	if err := c.do(ctx, "DELETE", deploymentPath(project, location, api, deployment).withVerb("deleteRevision"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
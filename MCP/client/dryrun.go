@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/names"
+)
+
+// Redacted replaces credentials in recorded requests.
+const Redacted = "REDACTED"
+
+// PlannedRequest is a request recorded instead of sent during a dry run.
+type PlannedRequest struct {
+	Method string            `json:"method"`
+	URL    string            `json:"url"`
+	Header map[string]string `json:"headers"`
+	Body   json.RawMessage   `json:"body,omitempty"`
+}
+
+// DryRun collects the requests a call would have sent.
+type DryRun struct {
+	Requests []PlannedRequest
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context in which requests other than GET are
+// validated and recorded in the returned DryRun instead of being sent. They
+// succeed with an empty response.
+func WithDryRun(ctx context.Context) (context.Context, *DryRun) {
+	d := &DryRun{}
+	return context.WithValue(ctx, dryRunKey{}, d), d
+}
+
+// record adds the request to the dry run in ctx, if any, and reports whether
+// it did.
+func record(ctx context.Context, cfg *config.APIConfig, method, target string, data []byte, accept string) (bool, error) {
+	d, ok := ctx.Value(dryRunKey{}).(*DryRun)
+	if !ok || method == http.MethodGet {
+		return false, nil
+	}
+	req, err := NewRequest(ctx, cfg, method, target, nil)
+	if err != nil {
+		return true, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", accept)
+
+	// Keep credentials out of the plan, which is shown to the user.
+	if req.Header.Get("Authorization") != "" {
+		req.Header.Set("Authorization", Redacted)
+	}
+	if cfg.APIKey != "" {
+		name := cfg.APIKeyName
+		if name == "" {
+			name = config.DefaultAPIKeyName
+		}
+		if cfg.APIKeyIn == config.APIKeyInQuery {
+			q := req.URL.Query()
+			q.Set(name, Redacted)
+			req.URL.RawQuery = q.Encode()
+		} else {
+			req.Header.Set(name, Redacted)
+		}
+	}
+
+	header := map[string]string{}
+	for k := range req.Header {
+		header[k] = req.Header.Get(k)
+	}
+	d.Requests = append(d.Requests, PlannedRequest{
+		Method: method,
+		URL:    req.URL.String(),
+		Header: header,
+		Body:   data,
+	})
+	return true, nil
+}
+
+// Get returns any registry resource by name, decoded generically. A revision
+// in the name selects that revision of a spec or deployment.
+func (c *Client) Get(ctx context.Context, name names.Name) (map[string]any, error) {
+	var p path
+	for i, s := range name.Segments {
+		id := s.ID
+		if i == len(name.Segments)-1 && name.Revision != "" {
+			id += "@" + name.Revision
+		}
+		p = p.child(s.Collection, id)
+	}
+	var out map[string]any
+	if err := c.do(ctx, "GET", p, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
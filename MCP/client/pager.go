@@ -0,0 +1,42 @@
+package client
+
+import "context"
+
+// DefaultMaxItems caps CollectPages when the caller does not set a limit.
+const DefaultMaxItems = 1000
+
+// CollectPages calls list for successive pages, following the next page token,
+// until the listing is exhausted or maxItems items have been gathered. opts
+// provides the filter, starting page token and page size; it is not modified.
+//
+// The page size of the last request is reduced so that no fetched item is
+// dropped, which keeps the returned next page token valid for resuming: it is
+// empty when the listing was exhausted.
+func CollectPages[T any](ctx context.Context, opts *ListOptions, maxItems int, list func(context.Context, *ListOptions) ([]T, string, error)) ([]T, string, error) {
+	if maxItems <= 0 {
+		maxItems = DefaultMaxItems
+	}
+	page := ListOptions{}
+	if opts != nil {
+		page = *opts
+	}
+	pageSize := page.PageSize
+
+	var items []T
+	for {
+		remaining := maxItems - len(items)
+		page.PageSize = pageSize
+		if pageSize == 0 || pageSize > remaining {
+			page.PageSize = remaining
+		}
+		batch, next, err := list(ctx, &page)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, batch...)
+		if next == "" || len(items) >= maxItems {
+			return items, next, nil
+		}
+		page.PageToken = next
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/registry-api/mcp-server/names"
+)
+
+// path is a request path below /v1, kept as collection/ID pairs so the IDs
+// can be validated and escaped when the request is built. It may end in a
+// bare collection (list and create) or a custom verb such as getContents.
+type path struct {
+	segments []string // collection, ID, collection, ID, ...
+	verb     string
+}
+
+// child returns the path of the resource id in collection below p.
+func (p path) child(collection, id string) path {
+	return path{segments: append(p.segments[:len(p.segments):len(p.segments)], collection, id)}
+}
+
+// collection returns the path of collection below p.
+func (p path) collection(collection string) path {
+	return path{segments: append(p.segments[:len(p.segments):len(p.segments)], collection)}
+}
+
+// withVerb returns p followed by the custom method verb, e.g. ":rollback".
+func (p path) withVerb(verb string) path {
+	p.verb = verb
+	return p
+}
+
+// build validates every ID against the AIP-122 format and returns the
+// escaped path.
+func (p path) build() (string, error) {
+	var b strings.Builder
+	b.WriteString("/v1")
+	for i, s := range p.segments {
+		b.WriteByte('/')
+		if i%2 == 0 {
+			b.WriteString(s)
+			continue
+		}
+		if err := names.ValidateID(p.segments[i-1], s); err != nil {
+			return "", err
+		}
+		b.WriteString(url.PathEscape(s))
+	}
+	if p.verb != "" {
+		b.WriteByte(':')
+		b.WriteString(p.verb)
+	}
+	return b.String(), nil
+}
+
+// route returns the path with every ID replaced by "*", e.g.
+// "/v1/projects/*/locations/*/apis/*:listRevisions", which identifies the
+// operation without the resource.
+func (p path) route() string {
+	var b strings.Builder
+	b.WriteString("/v1")
+	for i, s := range p.segments {
+		b.WriteByte('/')
+		if i%2 == 0 {
+			b.WriteString(s)
+		} else {
+			b.WriteByte('*')
+		}
+	}
+	if p.verb != "" {
+		b.WriteByte(':')
+		b.WriteString(p.verb)
+	}
+	return b.String()
+}
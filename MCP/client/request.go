@@ -0,0 +1,102 @@
+// Package client contains the shared plumbing used to talk to the upstream
+// Registry API.
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/registry-api/mcp-server/config"
+)
+
+// NewRequest builds a request against the registry with the JSON headers set
+// and the credentials configured in cfg applied.
+func NewRequest(ctx context.Context, cfg *config.APIConfig, method, rawURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if err := ApplyAuth(cfg, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ErrAccessToken wraps the errors of a token source. Requests failing with it
+// were never sent.
+var ErrAccessToken = errors.New("obtaining access token")
+
+// ApplyAuth adds the configured credentials to req. A bearer token, static or
+// from the token source, takes precedence over basic credentials since both use
+// the Authorization header; an API key is sent alongside either of them.
+func ApplyAuth(cfg *config.APIConfig, req *http.Request) error {
+	if cfg == nil {
+		return nil
+	}
+	switch {
+	case cfg.BearerToken != "":
+		token := strings.TrimSpace(cfg.BearerToken)
+		token = strings.TrimPrefix(token, "Bearer ")
+		req.Header.Set("Authorization", "Bearer "+token)
+	case cfg.TokenSource != nil:
+		tok, err := cfg.TokenSource.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrAccessToken, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+	case cfg.BasicAuth != "":
+		req.Header.Set("Authorization", "Basic "+basicCredentials(cfg.BasicAuth))
+	}
+
+	if cfg.APIKey != "" {
+		name := cfg.APIKeyName
+		if name == "" {
+			name = config.DefaultAPIKeyName
+		}
+		if cfg.APIKeyIn == config.APIKeyInQuery {
+			param := url.QueryEscape(name) + "=" + url.QueryEscape(cfg.APIKey)
+			if req.URL.RawQuery != "" {
+				param = "&" + param
+			}
+			req.URL.RawQuery += param
+		} else {
+			req.Header.Set(name, cfg.APIKey)
+		}
+	}
+	return nil
+}
+
+// dropToken invalidates the token req was sent with if it came from the
+// configured token source, reporting whether a fresh token can be tried.
+func dropToken(cfg *config.APIConfig, req *http.Request) bool {
+	if cfg == nil || cfg.BearerToken != "" || cfg.TokenSource == nil {
+		return false
+	}
+	src, ok := cfg.TokenSource.(interface{ Invalidate(accessToken string) })
+	if !ok {
+		return false
+	}
+	src.Invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+	return true
+}
+
+// basicCredentials accepts either "user:password" or an already base64-encoded
+// value and returns the encoded form expected after "Basic ".
+func basicCredentials(value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "Basic ")
+	if strings.Contains(value, ":") {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return value
+}
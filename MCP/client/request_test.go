@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/registry-api/mcp-server/config"
+	"golang.org/x/oauth2"
+)
+
+// rotatingSource hands out token-1, token-2, ... each valid for an hour.
+type rotatingSource struct{ n atomic.Int32 }
+
+func (s *rotatingSource) Token() (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", s.n.Add(1)), Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+// acceptOnly answers 401 unless the request carries token, counting requests.
+func acceptOnly(token string, requests *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, `{"error": {"code": 401, "status": "UNAUTHENTICATED"}}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"name": "projects/demo/locations/global/apis/petstore"}`)
+	})
+}
+
+func TestTokenRefreshedAfterUnauthorized(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(acceptOnly("token-2", &requests))
+	defer srv.Close()
+
+	src := &rotatingSource{}
+	c := New(&config.APIConfig{
+		BaseURL:     srv.URL,
+		TokenSource: config.NewReuseTokenSource(src, time.Minute),
+		Timeout:     5 * time.Second,
+		Retry:       config.RetryPolicy{MaxAttempts: 1},
+	})
+	if _, err := c.GetApi(context.Background(), "demo", "global", "petstore"); err != nil {
+		t.Fatalf("GetApi: %v", err)
+	}
+	if requests.Load() != 2 || src.n.Load() != 2 {
+		t.Errorf("sent %d requests with %d tokens, want 2 and 2", requests.Load(), src.n.Load())
+	}
+
+	// The refreshed token is reused.
+	if _, err := c.GetApi(context.Background(), "demo", "global", "petstore"); err != nil {
+		t.Fatalf("GetApi: %v", err)
+	}
+	if src.n.Load() != 2 {
+		t.Errorf("fetched %d tokens, want the refreshed one reused", src.n.Load())
+	}
+}
+
+func TestUnauthorizedRefreshedOnce(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(acceptOnly("never", &requests))
+	defer srv.Close()
+
+	c := New(&config.APIConfig{
+		BaseURL:     srv.URL,
+		TokenSource: config.NewReuseTokenSource(&rotatingSource{}, time.Minute),
+		Timeout:     5 * time.Second,
+		Retry:       config.RetryPolicy{MaxAttempts: 1},
+	})
+	_, err := c.GetApi(context.Background(), "demo", "global", "petstore")
+	if ErrorCode(err) != "UNAUTHENTICATED" {
+		t.Fatalf("GetApi error = %v, want UNAUTHENTICATED", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("sent %d requests, want 2", requests.Load())
+	}
+}
+
+func TestStaticTokenNotRefreshed(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(acceptOnly("token-1", &requests))
+	defer srv.Close()
+
+	// A BEARER_TOKEN takes precedence, so the source is never asked.
+	c := New(&config.APIConfig{
+		BaseURL:     srv.URL,
+		BearerToken: "static",
+		TokenSource: config.NewReuseTokenSource(&rotatingSource{}, time.Minute),
+		Timeout:     5 * time.Second,
+		Retry:       config.RetryPolicy{MaxAttempts: 1},
+	})
+	if _, err := c.GetApi(context.Background(), "demo", "global", "petstore"); err == nil {
+		t.Fatal("GetApi with a rejected static token succeeded")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("sent %d requests, want 1", requests.Load())
+	}
+}
+
+// failingSource never has a token.
+type failingSource struct{}
+
+func (failingSource) Token() (*oauth2.Token, error) {
+	return nil, errors.New("refresh token revoked")
+}
+
+func TestTokenSourceFailure(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(acceptOnly("token-1", &requests))
+	defer srv.Close()
+
+	c := New(&config.APIConfig{
+		BaseURL:     srv.URL,
+		TokenSource: failingSource{},
+		Timeout:     5 * time.Second,
+		Retry:       config.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	_, err := c.GetApi(context.Background(), "demo", "global", "petstore")
+	if !errors.Is(err, ErrAccessToken) || ErrorCode(err) != "UNAUTHENTICATED" {
+		t.Fatalf("GetApi error = %v (%s), want UNAUTHENTICATED", err, ErrorCode(err))
+	}
+	if requests.Load() != 0 {
+		t.Errorf("sent %d requests without a token, want none", requests.Load())
+	}
+}
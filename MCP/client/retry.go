@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/registry-api/mcp-server/config"
+)
+
+// isIdempotent reports whether a request may be safely repeated. Besides the
+// idempotent HTTP methods, tagging a revision is safe to repeat since applying
+// the same tag twice has the same effect as applying it once.
+func isIdempotent(method, path string) bool {
+	switch method {
+	case "GET", "DELETE", "PUT":
+		return true
+	case "POST":
+		return strings.HasSuffix(path, ":tagRevision")
+	}
+	return false
+}
+
+// retryDelay decides whether err is transient and, if so, how long to wait
+// before the next attempt. Server-provided delays (Retry-After or a
+// google.rpc.RetryInfo detail) win over the computed backoff, but the wait is
+// capped at the policy's MaxBackoff.
+func retryDelay(ctx context.Context, policy config.RetryPolicy, attempt int, err error) (time.Duration, bool) {
+	if ctx.Err() != nil {
+		return 0, false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+		if d, ok := serverDelay(apiErr); ok {
+			return min(d, policy.MaxBackoff), true
+		}
+		return backoff(policy, attempt), true
+	}
+	if transient(err) {
+		return backoff(policy, attempt), true
+	}
+	return 0, false
+}
+
+// transient reports whether a transport failure, surfaced as *url.Error by
+// http.Client.Do, may succeed when repeated: a timeout or a connection the
+// registry reset or closed. DNS failures, refused connections, unsupported
+// schemes and anything else (e.g. failing to obtain a token) are not worth
+// repeating.
+func transient(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return false
+	}
+	if urlErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff returns a "full jitter" exponential delay: a random duration in
+// [0, min(MaxBackoff, InitialBackoff*2^(attempt-1))).
+func backoff(policy config.RetryPolicy, attempt int) time.Duration {
+	ceiling := policy.InitialBackoff << (attempt - 1)
+	if ceiling <= 0 || ceiling > policy.MaxBackoff {
+		ceiling = policy.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// serverDelay extracts the delay requested by the registry, if any.
+func serverDelay(apiErr *Error) (time.Duration, bool) {
+	if v := apiErr.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return max(time.Until(at), 0), true
+		}
+	}
+	if info := apiErr.Status.RetryInfo; info != nil {
+		if d, err := time.ParseDuration(info.RetryDelay); err == nil {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/models"
+)
+
+// scripted is a registry answering the n-th request with the n-th handler
+// of its script, repeating the last one once the script runs out.
+type scripted struct {
+	mu       sync.Mutex
+	script   []http.HandlerFunc
+	requests []string // "METHOD path" of each request received
+}
+
+func (s *scripted) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	n := len(s.requests)
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	h := s.script[min(n, len(s.script)-1)]
+	s.mu.Unlock()
+	h(w, r)
+}
+
+func (s *scripted) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func fail(status int, retryAfter string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		http.Error(w, fmt.Sprintf(`{"error": {"code": %d, "message": "try again"}}`, status), status)
+	}
+}
+
+func ok(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, `{"name": "projects/demo/locations/global/apis/petstore"}`)
+}
+
+// reset closes the connection without answering.
+func reset(w http.ResponseWriter, r *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		panic(err)
+	}
+	conn.Close()
+}
+
+func newScripted(t *testing.T, policy config.RetryPolicy, script ...http.HandlerFunc) (*Client, *scripted) {
+	t.Helper()
+	s := &scripted{script: script}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return New(&config.APIConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, Retry: policy}), s
+}
+
+var fastRetries = config.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   []http.HandlerFunc
+		requests int
+		wantErr  string // ErrorCode of the error, "" for success
+	}{
+		{"429 with Retry-After", []http.HandlerFunc{fail(429, "0"), ok}, 2, ""},
+		{"503 with Retry-After date", []http.HandlerFunc{fail(503, time.Now().UTC().Format(http.TimeFormat)), ok}, 2, ""},
+		{"502 then 504", []http.HandlerFunc{fail(502, ""), fail(504, ""), ok}, 3, ""},
+		{"connection reset", []http.HandlerFunc{reset, ok}, 2, ""},
+		{"attempt limit", []http.HandlerFunc{fail(503, "")}, 3, "UNAVAILABLE"},
+		{"not transient", []http.HandlerFunc{fail(404, ""), ok}, 1, "NOT_FOUND"},
+		{"internal error", []http.HandlerFunc{fail(500, ""), ok}, 1, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, s := newScripted(t, fastRetries, tt.script...)
+			_, err := c.GetApi(context.Background(), "demo", "global", "petstore")
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("GetApi: %v", err)
+			case tt.wantErr != "" && ErrorCode(err) != tt.wantErr:
+				t.Errorf("GetApi error = %v, want %s", err, tt.wantErr)
+			}
+			if s.count() != tt.requests {
+				t.Errorf("sent %d requests, want %d: %v", s.count(), tt.requests, s.requests)
+			}
+		})
+	}
+}
+
+func TestRetryAfterCappedAtMaxBackoff(t *testing.T) {
+	c, s := newScripted(t, fastRetries, fail(429, "3600"), ok)
+	start := time.Now()
+	if _, err := c.GetApi(context.Background(), "demo", "global", "petstore"); err != nil {
+		t.Fatalf("GetApi: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v for Retry-After: 3600, want at most MaxBackoff", elapsed)
+	}
+	if s.count() != 2 {
+		t.Errorf("sent %d requests, want 2", s.count())
+	}
+}
+
+func TestRetryIdempotentOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(*Client) error
+		requests int
+	}{
+		{"create", func(c *Client) error {
+			_, err := c.CreateApi(context.Background(), "demo", "global", "petstore", &models.Api{})
+			return err
+		}, 1},
+		{"update", func(c *Client) error {
+			_, err := c.UpdateApi(context.Background(), "demo", "global", "petstore", &models.Api{}, nil)
+			return err
+		}, 1},
+		{"delete", func(c *Client) error {
+			return c.DeleteApi(context.Background(), "demo", "global", "petstore", false)
+		}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, s := newScripted(t, fastRetries, fail(503, "0"))
+			if err := tt.call(c); ErrorCode(err) != "UNAVAILABLE" {
+				t.Errorf("error = %v, want UNAVAILABLE", err)
+			}
+			if s.count() != tt.requests {
+				t.Errorf("sent %d requests, want %d: %v", s.count(), tt.requests, s.requests)
+			}
+		})
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	c, s := newScripted(t, config.RetryPolicy{MaxAttempts: 1}, fail(503, "0"), ok)
+	if _, err := c.GetApi(context.Background(), "demo", "global", "petstore"); err == nil {
+		t.Error("GetApi succeeded with retries disabled")
+	}
+	if s.count() != 1 {
+		t.Errorf("sent %d requests, want 1", s.count())
+	}
+}
+
+func TestTransient(t *testing.T) {
+	op := func(err error) error { return &url.Error{Op: "Get", URL: "http://registry.example", Err: err} }
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", op(os.ErrDeadlineExceeded), true},
+		{"connection reset", op(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), true},
+		{"connection closed", op(io.EOF), true},
+		{"connection refused", op(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), false},
+		{"DNS failure", op(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "registry.example", IsNotFound: true}}), false},
+		{"DNS timeout", op(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "i/o timeout", Name: "registry.example", IsTimeout: true}}), false},
+		{"unsupported scheme", op(errors.New(`unsupported protocol scheme "ftp"`)), false},
+		{"token", fmt.Errorf("%w: %w", ErrAccessToken, errors.New("expired")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transient(tt.err); got != tt.want {
+				t.Errorf("transient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
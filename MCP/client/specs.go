@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+func specPath(project, location, api, version, spec string) path {
+	return versionPath(project, location, api, version).child(names.Specs, spec)
+}
+
+// ListApiSpecs returns matching specs.
+func (c *Client) ListApiSpecs(ctx context.Context, project, location, api, version string, opts *ListOptions) (*models.ListApiSpecsResponse, error) {
+	var out models.ListApiSpecsResponse
+	if err := c.do(ctx, "GET", versionPath(project, location, api, version).collection(names.Specs), opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetApiSpec returns a specified spec.
+func (c *Client) GetApiSpec(ctx context.Context, project, location, api, version, spec string) (*models.ApiSpec, error) {
+	var out models.ApiSpec
+	if err := c.do(ctx, "GET", specPath(project, location, api, version, spec), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetApiSpecContents returns the contents of a specified spec.
+func (c *Client) GetApiSpecContents(ctx context.Context, project, location, api, version, spec string) (*HttpBody, error) {
+	return c.raw(ctx, specPath(project, location, api, version, spec).withVerb("getContents"))
+}
+
+// CreateApiSpec creates a specified spec.
+func (c *Client) CreateApiSpec(ctx context.Context, project, location, api, version, specID string, body *models.ApiSpec) (*models.ApiSpec, error) {
+	var out models.ApiSpec
+	query, err := idValues(names.Specs, "apiSpecId", specID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, "POST", versionPath(project, location, api, version).collection(names.Specs), query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateApiSpec can be used to modify a specified spec.
+func (c *Client) UpdateApiSpec(ctx context.Context, project, location, api, version, spec string, body *models.ApiSpec, opts *UpdateOptions) (*models.ApiSpec, error) {
+	var out models.ApiSpec
+	if err := c.do(ctx, "PATCH", specPath(project, location, api, version, spec), opts.values(), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteApiSpec removes a specified spec, all revisions, and all child
+// resources (e.g. artifacts).
+func (c *Client) DeleteApiSpec(ctx context.Context, project, location, api, version, spec string, force bool) error {
+	return c.do(ctx, "DELETE", specPath(project, location, api, version, spec), forceValues(force), nil, nil)
+}
+
+// ListApiSpecRevisions lists all revisions of a spec, most recent first.
+func (c *Client) ListApiSpecRevisions(ctx context.Context, project, location, api, version, spec string, opts *ListOptions) (*models.ListApiSpecRevisionsResponse, error) {
+	var out models.ListApiSpecRevisionsResponse
+	if err := c.do(ctx, "GET", specPath(project, location, api, version, spec).withVerb("listRevisions"), opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TagApiSpecRevision adds a tag to a specified revision of a spec.
+func (c *Client) TagApiSpecRevision(ctx context.Context, project, location, api, version, spec string, body *models.TagApiSpecRevisionRequest) (*models.ApiSpec, error) {
+	var out models.ApiSpec
+	if err := c.do(ctx, "POST", specPath(project, location, api, version, spec).withVerb("tagRevision"), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RollbackApiSpec sets the current revision to a specified prior revision.
+// This creates a new revision with a new revision ID.
+func (c *Client) RollbackApiSpec(ctx context.Context, project, location, api, version, spec string, body *models.RollbackApiSpecRequest) (*models.ApiSpec, error) {
+	var out models.ApiSpec
+	if err := c.do(ctx, "POST", specPath(project, location, api, version, spec).withVerb("rollback"), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteApiSpecRevision deletes a revision of a spec. spec must carry the
+// revision, e.g. "openapi@c7cfa2a8".
+func (c *Client) DeleteApiSpecRevision(ctx context.Context, project, location, api, version, spec string) (*models.ApiSpec, error) {
+	var out models.ApiSpec
+	if err := c.do(ctx, "DELETE", specPath(project, location, api, version, spec).withVerb("deleteRevision"), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// codeNames holds the google.rpc.Code names indexed by their numeric value.
+var codeNames = [...]string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// httpCodes maps HTTP status codes to the google.rpc.Code used when an error
+// body does not carry one.
+var httpCodes = map[int]int{
+	http.StatusBadRequest:          3,  // INVALID_ARGUMENT
+	http.StatusUnauthorized:        16, // UNAUTHENTICATED
+	http.StatusForbidden:           7,  // PERMISSION_DENIED
+	http.StatusNotFound:            5,  // NOT_FOUND
+	http.StatusConflict:            6,  // ALREADY_EXISTS
+	http.StatusPreconditionFailed:  9,  // FAILED_PRECONDITION
+	http.StatusTooManyRequests:     8,  // RESOURCE_EXHAUSTED
+	499:                            1,  // CANCELLED (client closed request)
+	http.StatusInternalServerError: 13, // INTERNAL
+	http.StatusNotImplemented:      12, // UNIMPLEMENTED
+	http.StatusBadGateway:          14, // UNAVAILABLE
+	http.StatusServiceUnavailable:  14, // UNAVAILABLE
+	http.StatusGatewayTimeout:      4,  // DEADLINE_EXCEEDED
+}
+
+// CodeName returns the google.rpc.Code name for code, e.g. "NOT_FOUND".
+func CodeName(code int) string {
+	if code >= 0 && code < len(codeNames) {
+		return codeNames[code]
+	}
+	return "UNKNOWN"
+}
+
+// ErrorCode returns the google.rpc.Code name describing err, an error
+// returned by a Client: the status of a registry error, DEADLINE_EXCEEDED or
+// CANCELLED if the request's context ended, UNAUTHENTICATED if no access
+// token could be obtained for it, and UNAVAILABLE if the registry could not be
+// reached.
+func ErrorCode(err error) string {
+	var apiErr *Error
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.Status.Status
+	case errors.Is(err, context.DeadlineExceeded):
+		return "DEADLINE_EXCEEDED"
+	case errors.Is(err, context.Canceled):
+		return "CANCELLED"
+	case errors.Is(err, ErrAccessToken):
+		return "UNAUTHENTICATED"
+	}
+	return "UNAVAILABLE"
+}
+
+// Status is a decoded google.rpc.Status error payload. The well-known detail
+// messages are decoded into typed fields; all details are also kept as
+// received in Details.
+type Status struct {
+	HTTPStatus int               `json:"httpStatus"`
+	Code       int               `json:"code"`
+	Status     string            `json:"status"`
+	Message    string            `json:"message,omitempty"`
+	BadRequest *BadRequest       `json:"badRequest,omitempty"`
+	ErrorInfo  *ErrorInfo        `json:"errorInfo,omitempty"`
+	RetryInfo  *RetryInfo        `json:"retryInfo,omitempty"`
+	Details    []json.RawMessage `json:"details,omitempty"`
+}
+
+// BadRequest is the google.rpc.BadRequest error detail.
+type BadRequest struct {
+	FieldViolations []FieldViolation `json:"fieldViolations,omitempty"`
+}
+
+// FieldViolation describes a single invalid request field.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// ErrorInfo is the google.rpc.ErrorInfo error detail.
+type ErrorInfo struct {
+	Reason   string            `json:"reason"`
+	Domain   string            `json:"domain,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// RetryInfo is the google.rpc.RetryInfo error detail.
+type RetryInfo struct {
+	RetryDelay string `json:"retryDelay"`
+}
+
+// rpcStatus is the wire form of google.rpc.Status. Google REST endpoints
+// wrap it as {"error": {...}} with code holding the HTTP status and the
+// code name in status; a bare Status carries the google.rpc.Code in code.
+type rpcStatus struct {
+	Code    int               `json:"code"`
+	Status  string            `json:"status"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details"`
+}
+
+// decodeStatus parses an error response body. Bodies that are not a Status
+// still yield a usable Status derived from the HTTP status code.
+func decodeStatus(httpStatus int, body []byte) Status {
+	s := Status{HTTPStatus: httpStatus, Code: 2}
+	if code, ok := httpCodes[httpStatus]; ok {
+		s.Code = code
+	}
+
+	var envelope struct {
+		Error *rpcStatus `json:"error"`
+	}
+	var wire rpcStatus
+	switch {
+	case json.Unmarshal(body, &envelope) == nil && envelope.Error != nil:
+		wire = *envelope.Error
+		wire.Code = 0 // the HTTP status, not a google.rpc.Code
+	case json.Unmarshal(body, &wire) == nil:
+	default:
+		s.Message = strings.TrimSpace(string(body))
+		s.Status = CodeName(s.Code)
+		return s
+	}
+
+	if wire.Status != "" {
+		for code, name := range codeNames {
+			if name == wire.Status {
+				s.Code = code
+			}
+		}
+	} else if wire.Code > 0 {
+		s.Code = wire.Code
+	}
+	s.Status = CodeName(s.Code)
+	s.Message = wire.Message
+	s.Details = wire.Details
+
+	for _, raw := range wire.Details {
+		var typ struct {
+			Type string `json:"@type"`
+		}
+		if json.Unmarshal(raw, &typ) != nil {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(typ.Type, "google.rpc.BadRequest"):
+			s.BadRequest = &BadRequest{}
+			json.Unmarshal(raw, s.BadRequest)
+		case strings.HasSuffix(typ.Type, "google.rpc.ErrorInfo"):
+			s.ErrorInfo = &ErrorInfo{}
+			json.Unmarshal(raw, s.ErrorInfo)
+		case strings.HasSuffix(typ.Type, "google.rpc.RetryInfo"):
+			s.RetryInfo = &RetryInfo{}
+			json.Unmarshal(raw, s.RetryInfo)
+		}
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/registry-api/mcp-server/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the client's spans.
+const tracerName = "github.com/registry-api/mcp-server/client"
+
+// Call describes a request sent upstream, including any retries.
+type Call struct {
+	Method   string
+	Path     string
+	Status   int           // Status code of the last response; 0 if none was received
+	Latency  time.Duration // Time from the first attempt until the last one finished
+	Attempts int
+	Err      error
+}
+
+// Trace collects the requests sent upstream on behalf of a context. Traces
+// nest: a request is recorded in every trace of its context.
+type Trace struct {
+	parent *Trace
+	mu     sync.Mutex
+	calls  []Call
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context whose requests are recorded in the returned
+// Trace once they complete.
+func WithTrace(ctx context.Context) (context.Context, *Trace) {
+	parent, _ := ctx.Value(traceKey{}).(*Trace)
+	t := &Trace{parent: parent}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+// Calls returns the requests recorded so far, oldest first.
+func (t *Trace) Calls() []Call {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Call(nil), t.calls...)
+}
+
+func traceCall(ctx context.Context, c Call) {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	for ; t != nil; t = t.parent {
+		t.mu.Lock()
+		t.calls = append(t.calls, c)
+		t.mu.Unlock()
+	}
+}
+
+// startSpan starts the span of one attempt of a request to the registry, a
+// child of the span in ctx, e.g. that of a tool call. Retries and further
+// pages get spans of their own.
+func startSpan(ctx context.Context, method, route, target string, attempt int) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", method),
+		attribute.String("url.template", route),
+		attribute.String("url.full", target),
+	}
+	if attempt > 1 {
+		attrs = append(attrs, attribute.Int("http.request.resend_count", attempt-1))
+	}
+	return telemetry.Tracer(tracerName).Start(ctx, method+" "+route,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan records the outcome of an attempt and ends its span.
+func endSpan(span trace.Span, status int, err error) {
+	if status != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+	}
+	if err != nil {
+		span.SetAttributes(attribute.String("error.type", ErrorCode(err)))
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
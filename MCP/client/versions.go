@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+func versionPath(project, location, api, version string) path {
+	return apiPath(project, location, api).child(names.Versions, version)
+}
+
+// ListApiVersions returns matching versions.
+func (c *Client) ListApiVersions(ctx context.Context, project, location, api string, opts *ListOptions) (*models.ListApiVersionsResponse, error) {
+	var out models.ListApiVersionsResponse
+	if err := c.do(ctx, "GET", apiPath(project, location, api).collection(names.Versions), opts.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetApiVersion returns a specified version.
+func (c *Client) GetApiVersion(ctx context.Context, project, location, api, version string) (*models.ApiVersion, error) {
+	var out models.ApiVersion
+	if err := c.do(ctx, "GET", versionPath(project, location, api, version), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateApiVersion creates a specified version.
+func (c *Client) CreateApiVersion(ctx context.Context, project, location, api, versionID string, body *models.ApiVersion) (*models.ApiVersion, error) {
+	var out models.ApiVersion
+	query, err := idValues(names.Versions, "apiVersionId", versionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, "POST", apiPath(project, location, api).collection(names.Versions), query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateApiVersion can be used to modify a specified version.
+func (c *Client) UpdateApiVersion(ctx context.Context, project, location, api, version string, body *models.ApiVersion, opts *UpdateOptions) (*models.ApiVersion, error) {
+	var out models.ApiVersion
+	if err := c.do(ctx, "PATCH", versionPath(project, location, api, version), opts.values(), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteApiVersion removes a specified version and all of the resources that
+// it owns.
+func (c *Client) DeleteApiVersion(ctx context.Context, project, location, api, version string, force bool) error {
+	return c.do(ctx, "DELETE", versionPath(project, location, api, version), forceValues(force), nil, nil)
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/registry-api/mcp-server/cache"
+)
+
+// DefaultCacheMaxEntries bounds the response cache when CACHE_MAX_ENTRIES is
+// not set.
+const DefaultCacheMaxEntries = 1000
+
+// loadCache reads CACHE_TTL and CACHE_MAX_ENTRIES. Caching is disabled, and
+// loadCache returns nil, unless CACHE_TTL is positive.
+func loadCache() (*cache.Cache, error) {
+	ttl, err := durationEnv("CACHE_TTL", 0)
+	if err != nil {
+		return nil, err
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("invalid CACHE_TTL %v: must not be negative", ttl)
+	}
+	maxEntries := DefaultCacheMaxEntries
+	if v := os.Getenv("CACHE_MAX_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid CACHE_MAX_ENTRIES %q: must be a positive integer", v)
+		}
+		maxEntries = n
+	}
+	if ttl == 0 {
+		return nil, nil
+	}
+	return cache.New(ttl, maxEntries), nil
+}
@@ -3,46 +3,237 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/registry-api/mcp-server/audit"
+	"github.com/registry-api/mcp-server/cache"
+	"golang.org/x/oauth2"
+)
+
+// Supported values for APIConfig.APIKeyIn.
+const (
+	APIKeyInHeader = "header"
+	APIKeyInQuery  = "query"
+)
+
+// Supported values for APIConfig.ToolNaming.
+const (
+	ToolNamingShort       = "short"       // e.g. get_spec_contents
+	ToolNamingOperationID = "operationId" // e.g. Registry_GetApiSpecContents
+	ToolNamingLegacy      = "legacy"      // path-based, e.g. get_v1_projects_project_..._specs_spec:getContents
 )
 
+// DefaultAPIKeyName is the header (or query parameter) used to send API_KEY
+// when API_KEY_NAME is not set.
+const DefaultAPIKeyName = "X-Goog-Api-Key"
+
 type APIConfig struct {
 	BaseURL     string
-	BearerToken string // For OAuth2/Bearer authentication
-	APIKey      string // For API key authentication
-	BasicAuth   string // For basic authentication
-	Port        string // For server port configuration
+	BearerToken string             // For OAuth2/Bearer authentication
+	TokenSource oauth2.TokenSource // Refreshing bearer token source, used when BearerToken is empty
+	APIKey      string             // For API key authentication
+	APIKeyName  string             // Header or query parameter name carrying APIKey
+	APIKeyIn    string             // Where APIKey is sent: "header" or "query"
+	BasicAuth   string             // For basic authentication ("user:password" or pre-encoded)
+	Port        string             // For server port configuration
+	Timeout     time.Duration      // Per-request timeout for upstream registry calls
+	Retry       RetryPolicy        // Retry behaviour for transient upstream failures
+
+	DefaultProject  string   // Project used when a call names none; its APIs and specs are listed as MCP resources
+	DefaultLocation string   // Location used when a call names none
+	AllowedProjects []string // Projects calls may target; empty allows any project
+
+	WatchInterval time.Duration // How often subscribed resources are polled for changes
+	ToolNaming    string        // Naming scheme tools are registered under
+	ToolFilters   []ToolFilter  // A tool is registered only if every filter allows it
+
+	ConfirmDestructive bool // Deletes and rollbacks must be confirmed before they run
+	DryRun             bool // Mutating tools only report what they would send
+
+	Audit audit.Sink   // Receives a record of every mutating tool call; nil disables auditing
+	Cache *cache.Cache // Shared cache of registry reads; nil disables caching
+
+	SessionIdleTimeout time.Duration // HTTP mode: sessions idle for longer are closed; 0 keeps them forever
 }
 
+// DefaultLocationID is used when DEFAULT_LOCATION is not set.
+const DefaultLocationID = "global"
+
+// DefaultTimeout bounds each upstream request when REQUEST_TIMEOUT is not set.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultSessionIdleTimeout closes idle HTTP sessions when
+// SESSION_IDLE_TIMEOUT is not set.
+const DefaultSessionIdleTimeout = 30 * time.Minute
+
+// DefaultWatchInterval is the polling interval for subscribed resources when
+// RESOURCE_POLL_INTERVAL is not set.
+const DefaultWatchInterval = 30 * time.Second
+
 func LoadAPIConfig() (*APIConfig, error) {
 	// Check port environment variable (both uppercase and lowercase)
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = os.Getenv("port")
 	}
-	
+
 	baseURL := os.Getenv("API_BASE_URL")
-	
+
 	// Check transport environment variable (both uppercase and lowercase)
 	transport := os.Getenv("TRANSPORT")
 	if transport == "" {
 		transport = os.Getenv("transport")
 	}
-	
+
 	// For STDIO mode (transport is not "http"/"HTTP"/"https"/"HTTPS"), API_BASE_URL is required from environment
 	if transport != "http" && transport != "HTTP" && transport != "https" && transport != "HTTPS" && baseURL == "" {
 		return nil, fmt.Errorf("API_BASE_URL environment variable not set")
 	}
-	
+
 	// For HTTP/HTTPS mode (transport is "http"/"HTTP"/"https"/"HTTPS"), API_BASE_URL comes from headers
 	// so we don't require it from environment variables
 
+	apiKeyName := os.Getenv("API_KEY_NAME")
+	if apiKeyName == "" {
+		apiKeyName = DefaultAPIKeyName
+	}
+	apiKeyIn := os.Getenv("API_KEY_IN")
+	if apiKeyIn == "" {
+		apiKeyIn = APIKeyInHeader
+	}
+	if apiKeyIn != APIKeyInHeader && apiKeyIn != APIKeyInQuery {
+		return nil, fmt.Errorf("invalid API_KEY_IN %q: must be %q or %q", apiKeyIn, APIKeyInHeader, APIKeyInQuery)
+	}
+
+	tokenSource, err := loadTokenSource()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := durationEnv("REQUEST_TIMEOUT", DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	retry, err := loadRetryPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	watchInterval, err := durationEnv("RESOURCE_POLL_INTERVAL", DefaultWatchInterval)
+	if err != nil {
+		return nil, err
+	}
+	if watchInterval <= 0 {
+		return nil, fmt.Errorf("invalid RESOURCE_POLL_INTERVAL %v: must be positive", watchInterval)
+	}
+
+	sessionIdleTimeout, err := durationEnv("SESSION_IDLE_TIMEOUT", DefaultSessionIdleTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if sessionIdleTimeout < 0 {
+		return nil, fmt.Errorf("invalid SESSION_IDLE_TIMEOUT %v: must not be negative", sessionIdleTimeout)
+	}
+
+	toolNaming := os.Getenv("TOOL_NAMING")
+	if toolNaming == "" {
+		toolNaming = ToolNamingShort
+	}
+	if toolNaming != ToolNamingShort && toolNaming != ToolNamingOperationID && toolNaming != ToolNamingLegacy {
+		return nil, fmt.Errorf("invalid TOOL_NAMING %q: must be %q, %q or %q", toolNaming, ToolNamingShort, ToolNamingOperationID, ToolNamingLegacy)
+	}
+
+	toolFilter, err := LoadToolFilter(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmDestructive := true
+	if v := os.Getenv("CONFIRM_DESTRUCTIVE"); v != "" {
+		if confirmDestructive, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid CONFIRM_DESTRUCTIVE %q: %w", v, err)
+		}
+	}
+
+	dryRun := false
+	if v := os.Getenv("DRY_RUN"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid DRY_RUN %q: %w", v, err)
+		}
+	}
+
+	auditDest := os.Getenv("AUDIT_LOG")
+	if auditDest == audit.Stdout && transport != "http" && transport != "HTTP" && transport != "https" && transport != "HTTPS" {
+		return nil, fmt.Errorf("AUDIT_LOG=stdout cannot be used in STDIO mode, where stdout carries the MCP protocol; use stderr or a file")
+	}
+	auditSink, err := audit.Open(auditDest)
+	if err != nil {
+		return nil, err
+	}
+
+	responseCache, err := loadCache()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultLocation := os.Getenv("DEFAULT_LOCATION")
+	if defaultLocation == "" {
+		defaultLocation = DefaultLocationID
+	}
+
+	var allowedProjects []string
+	for _, p := range strings.Split(os.Getenv("ALLOWED_PROJECTS"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			allowedProjects = append(allowedProjects, p)
+		}
+	}
+	defaultProject := os.Getenv("DEFAULT_PROJECT")
+	if err := checkProject(allowedProjects, defaultProject); defaultProject != "" && err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_PROJECT: %w", err)
+	}
+
 	return &APIConfig{
 		BaseURL:     baseURL,
 		BearerToken: os.Getenv("BEARER_TOKEN"),
+		TokenSource: tokenSource,
 		APIKey:      os.Getenv("API_KEY"),
+		APIKeyName:  apiKeyName,
+		APIKeyIn:    apiKeyIn,
 		BasicAuth:   os.Getenv("BASIC_AUTH"),
 		Port:        port,
+		Timeout:     timeout,
+		Retry:       retry,
+
+		DefaultProject:  defaultProject,
+		DefaultLocation: defaultLocation,
+		AllowedProjects: allowedProjects,
+
+		WatchInterval: watchInterval,
+		ToolNaming:    toolNaming,
+		ToolFilters:   []ToolFilter{toolFilter},
+
+		ConfirmDestructive: confirmDestructive,
+		DryRun:             dryRun,
+
+		Audit: auditSink,
+		Cache: responseCache,
+
+		SessionIdleTimeout: sessionIdleTimeout,
 	}, nil
 }
 
+// CheckProject returns an error if project is not one of AllowedProjects.
+func (c *APIConfig) CheckProject(project string) error {
+	return checkProject(c.AllowedProjects, project)
+}
 
+func checkProject(allowed []string, project string) error {
+	if len(allowed) == 0 || slices.Contains(allowed, project) {
+		return nil
+	}
+	return fmt.Errorf("project %q is not allowed; allowed projects: %s", project, strings.Join(allowed, ", "))
+}
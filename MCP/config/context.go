@@ -0,0 +1,22 @@
+package config
+
+import "context"
+
+// contextKey is the context key under which HTTP mode stores the
+// configuration of a request or its session.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying cfg, which then takes precedence
+// over the server's configuration for anything done on behalf of ctx.
+func NewContext(ctx context.Context, cfg *APIConfig) context.Context {
+	return context.WithValue(ctx, contextKey{}, cfg)
+}
+
+// FromContext returns the configuration carried by ctx, or def if there is
+// none.
+func FromContext(ctx context.Context, def *APIConfig) *APIConfig {
+	if cfg, ok := ctx.Value(contextKey{}).(*APIConfig); ok && cfg != nil {
+		return cfg
+	}
+	return def
+}
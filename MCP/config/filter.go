@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Prefixes selecting what a tool pattern is matched against. Patterns without
+// a prefix match the tool's name under any naming scheme.
+const (
+	ToolPatternVerb     = "verb:"     // e.g. verb:delete
+	ToolPatternResource = "resource:" // e.g. resource:artifact
+)
+
+// ToolFilter selects the tools that are registered. Patterns are globs as
+// understood by path.Match, e.g. "delete_*", "verb:rollback" or
+// "resource:artifact".
+type ToolFilter struct {
+	ReadOnly bool     // Only tools that do not modify the registry
+	Enabled  []string // Only tools matching one of these; empty enables all
+	Disabled []string // No tools matching one of these
+}
+
+// LoadToolFilter builds a ToolFilter from the READ_ONLY, ENABLED_TOOLS and
+// DISABLED_TOOLS values returned by get, which may read the environment or
+// request headers.
+func LoadToolFilter(get func(string) string) (ToolFilter, error) {
+	var f ToolFilter
+	if v := get("READ_ONLY"); v != "" {
+		readOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid READ_ONLY %q: %w", v, err)
+		}
+		f.ReadOnly = readOnly
+	}
+	var err error
+	if f.Enabled, err = toolPatterns("ENABLED_TOOLS", get("ENABLED_TOOLS")); err != nil {
+		return f, err
+	}
+	if f.Disabled, err = toolPatterns("DISABLED_TOOLS", get("DISABLED_TOOLS")); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+func toolPatterns(name, value string) ([]string, error) {
+	var patterns []string
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		glob := strings.TrimPrefix(strings.TrimPrefix(p, ToolPatternVerb), ToolPatternResource)
+		// Legacy tool names contain ':' too, but always after an '_'.
+		if prefix, _, ok := strings.Cut(glob, ":"); ok && !strings.Contains(prefix, "_") {
+			return nil, fmt.Errorf("invalid %s pattern %q: unknown prefix; use %q or %q", name, p, ToolPatternVerb, ToolPatternResource)
+		}
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", name, p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// Allows reports whether f registers a tool. names holds the tool's names
+// under every naming scheme; verb and resource describe the operation, e.g.
+// "delete" and "artifact"; readOnly tells whether it leaves the registry
+// unchanged.
+func (f ToolFilter) Allows(names []string, verb, resource string, readOnly bool) bool {
+	if f.ReadOnly && !readOnly {
+		return false
+	}
+	if len(f.Enabled) > 0 && !matchTool(f.Enabled, names, verb, resource) {
+		return false
+	}
+	return !matchTool(f.Disabled, names, verb, resource)
+}
+
+func matchTool(patterns, names []string, verb, resource string) bool {
+	for _, p := range patterns {
+		candidates := names
+		switch {
+		case strings.HasPrefix(p, ToolPatternVerb):
+			p, candidates = strings.TrimPrefix(p, ToolPatternVerb), []string{verb}
+		case strings.HasPrefix(p, ToolPatternResource):
+			p, candidates = strings.TrimPrefix(p, ToolPatternResource), []string{resource}
+		}
+		for _, c := range candidates {
+			if ok, _ := path.Match(p, c); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
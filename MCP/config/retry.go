@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how transient upstream failures (429, 502, 503, 504 and
+// connection errors) are retried. Only idempotent requests are retried.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts including the first; 1 disables retries
+	InitialBackoff time.Duration // Upper bound of the first jittered delay
+	MaxBackoff     time.Duration // Cap for computed and server-requested delays
+}
+
+// DefaultRetryPolicy is used for settings not given in the environment.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+}
+
+// loadRetryPolicy reads RETRY_MAX_ATTEMPTS, RETRY_INITIAL_BACKOFF and
+// RETRY_MAX_BACKOFF.
+func loadRetryPolicy() (RetryPolicy, error) {
+	policy := DefaultRetryPolicy
+	if v := os.Getenv("RETRY_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return policy, fmt.Errorf("invalid RETRY_MAX_ATTEMPTS %q: must be a positive integer", v)
+		}
+		policy.MaxAttempts = n
+	}
+	var err error
+	if policy.InitialBackoff, err = durationEnv("RETRY_INITIAL_BACKOFF", policy.InitialBackoff); err != nil {
+		return policy, err
+	}
+	if policy.MaxBackoff, err = durationEnv("RETRY_MAX_BACKOFF", policy.MaxBackoff); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
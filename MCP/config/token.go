@@ -0,0 +1,261 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
+)
+
+// DefaultTokenScope is requested for service-account tokens when TOKEN_SCOPES
+// is not set.
+const DefaultTokenScope = "https://www.googleapis.com/auth/cloud-platform"
+
+const (
+	defaultTokenRefreshMargin = 5 * time.Minute
+	defaultTokenCommandTTL    = 10 * time.Minute
+)
+
+// loadTokenSource builds the refreshing bearer token source configured through
+// the environment. It returns nil when no dynamic source is configured, in
+// which case the static BEARER_TOKEN (if any) is used as-is.
+//
+// Exactly one of the following may be set:
+//   - SERVICE_ACCOUNT_KEY_FILE: path to a Google service-account JSON key
+//   - TOKEN_METADATA_URL: a metadata-server style endpoint returning
+//     {"access_token": ..., "expires_in": ...}
+//   - TOKEN_COMMAND: a shell command printing an access token on stdout, such
+//     as "gcloud auth print-access-token"
+func loadTokenSource() (oauth2.TokenSource, error) {
+	keyFile := os.Getenv("SERVICE_ACCOUNT_KEY_FILE")
+	metadataURL := os.Getenv("TOKEN_METADATA_URL")
+	command := os.Getenv("TOKEN_COMMAND")
+
+	configured := 0
+	for _, v := range []string{keyFile, metadataURL, command} {
+		if v != "" {
+			configured++
+		}
+	}
+	if configured == 0 {
+		return nil, nil
+	}
+	if configured > 1 {
+		return nil, fmt.Errorf("only one of SERVICE_ACCOUNT_KEY_FILE, TOKEN_METADATA_URL and TOKEN_COMMAND may be set")
+	}
+
+	margin, err := durationEnv("TOKEN_REFRESH_MARGIN", defaultTokenRefreshMargin)
+	if err != nil {
+		return nil, err
+	}
+
+	var src oauth2.TokenSource
+	switch {
+	case keyFile != "":
+		src, err = NewServiceAccountTokenSource(keyFile, tokenScopes()...)
+	case metadataURL != "":
+		src = NewMetadataTokenSource(metadataURL)
+	case command != "":
+		var ttl time.Duration
+		ttl, err = durationEnv("TOKEN_COMMAND_TTL", defaultTokenCommandTTL)
+		src = NewCommandTokenSource(command, ttl)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewReuseTokenSource(src, margin), nil
+}
+
+// ReuseTokenSource caches the tokens of another source until margin before
+// they expire. Unlike oauth2.ReuseTokenSource, a token the registry rejects
+// can be dropped with Invalidate so that the next call fetches a new one.
+type ReuseTokenSource struct {
+	src    oauth2.TokenSource
+	margin time.Duration
+
+	mu  sync.Mutex
+	tok *oauth2.Token
+}
+
+// NewReuseTokenSource returns a source caching the tokens of src.
+func NewReuseTokenSource(src oauth2.TokenSource, margin time.Duration) *ReuseTokenSource {
+	return &ReuseTokenSource{src: src, margin: margin}
+}
+
+// Token returns the cached token, fetching a new one once it is about to
+// expire.
+func (s *ReuseTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok != nil && (s.tok.Expiry.IsZero() || time.Now().Add(s.margin).Before(s.tok.Expiry)) {
+		return s.tok, nil
+	}
+	tok, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.tok = tok
+	return tok, nil
+}
+
+// Invalidate drops the cached token if it is still accessToken. A token
+// fetched since then by a concurrent caller is kept.
+func (s *ReuseTokenSource) Invalidate(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok != nil && s.tok.AccessToken == accessToken {
+		s.tok = nil
+	}
+}
+
+// NewServiceAccountTokenSource mints access tokens from a Google
+// service-account JSON key file. The key's token_uri is honoured, so a local
+// token endpoint can stand in for Google's in tests.
+func NewServiceAccountTokenSource(keyFile string, scopes ...string) (oauth2.TokenSource, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading service account key: %w", err)
+	}
+	if len(scopes) == 0 {
+		scopes = []string{DefaultTokenScope}
+	}
+	jwtCfg, err := google.JWTConfigFromJSON(data, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing service account key: %w", err)
+	}
+	return &serviceAccountTokenSource{cfg: jwtCfg}, nil
+}
+
+// serviceAccountTokenSource mints a new token on every call. The source
+// returned by jwt.Config.TokenSource caches its token, which would keep a
+// ReuseTokenSource around it from refreshing early or after Invalidate.
+type serviceAccountTokenSource struct {
+	cfg *jwt.Config
+}
+
+func (s *serviceAccountTokenSource) Token() (*oauth2.Token, error) {
+	return s.cfg.TokenSource(context.Background()).Token()
+}
+
+// NewMetadataTokenSource fetches tokens from a GCE metadata-server style
+// endpoint, e.g.
+// http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token
+func NewMetadataTokenSource(url string) oauth2.TokenSource {
+	return &metadataTokenSource{url: url, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type metadataTokenSource struct {
+	url    string
+	client *http.Client
+}
+
+func (s *metadataTokenSource) Token() (*oauth2.Token, error) {
+	req, err := http.NewRequest("GET", s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching token from %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetching token from %s: status %d", s.url, resp.StatusCode)
+	}
+	var body tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding token response: %w", err)
+	}
+	return body.token(0)
+}
+
+// NewCommandTokenSource runs command with "sh -c", so it may quote arguments
+// and use pipes, and uses its trimmed stdout as the access token. If the
+// output is a JSON token response its expires_in is used, otherwise the token
+// is assumed to be valid for ttl.
+func NewCommandTokenSource(command string, ttl time.Duration) oauth2.TokenSource {
+	return &commandTokenSource{command: command, ttl: ttl}
+}
+
+type commandTokenSource struct {
+	command string
+	ttl     time.Duration
+}
+
+func (s *commandTokenSource) Token() (*oauth2.Token, error) {
+	if strings.TrimSpace(s.command) == "" {
+		return nil, fmt.Errorf("empty TOKEN_COMMAND")
+	}
+	out, err := exec.Command("sh", "-c", s.command).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("running TOKEN_COMMAND: %w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("running TOKEN_COMMAND: %w", err)
+	}
+	out = bytes.TrimSpace(out)
+
+	var body tokenResponse
+	if json.Unmarshal(out, &body) == nil && body.AccessToken != "" {
+		return body.token(s.ttl)
+	}
+	return (&tokenResponse{AccessToken: string(out)}).token(s.ttl)
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+// token converts the response into an oauth2.Token, falling back to ttl when
+// the response carries no lifetime. A zero lifetime means "never expires".
+func (r *tokenResponse) token(ttl time.Duration) (*oauth2.Token, error) {
+	if r.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+	tok := &oauth2.Token{AccessToken: r.AccessToken, TokenType: r.TokenType}
+	lifetime := time.Duration(r.ExpiresIn) * time.Second
+	if lifetime == 0 {
+		lifetime = ttl
+	}
+	if lifetime > 0 {
+		tok.Expiry = time.Now().Add(lifetime)
+	}
+	return tok, nil
+}
+
+func tokenScopes() []string {
+	var scopes []string
+	for _, s := range strings.Split(os.Getenv("TOKEN_SCOPES"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	return d, nil
+}
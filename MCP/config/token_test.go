@@ -0,0 +1,262 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestMetadataTokenSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing Metadata-Flavor", http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "meta-token", "expires_in": 3599, "token_type": "Bearer"}`)
+	}))
+	defer srv.Close()
+
+	tok, err := NewMetadataTokenSource(srv.URL).Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "meta-token" || tok.TokenType != "Bearer" {
+		t.Errorf("token = %+v, want meta-token of type Bearer", tok)
+	}
+	if d := time.Until(tok.Expiry); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("token expires in %v, want about 1h", d)
+	}
+}
+
+func TestMetadataTokenSourceError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no service account", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if _, err := NewMetadataTokenSource(srv.URL).Token(); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Token() error = %v, want status 404", err)
+	}
+}
+
+// serviceAccountKey writes a service-account key file whose token_uri is a
+// local endpoint handing out sa-token-1, sa-token-2, ... valid for lifetime.
+// It returns the file and the number of grants made so far.
+func serviceAccountKey(t *testing.T, lifetime time.Duration) (string, *atomic.Int32) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grants := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.Form.Get("assertion") == "" {
+			http.Error(w, "unexpected grant", http.StatusBadRequest)
+			return
+		}
+		n := grants.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "sa-token-%d", "expires_in": %d, "token_type": "Bearer"}`, n, int(lifetime.Seconds()))
+	}))
+	t.Cleanup(srv.Close)
+
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	data, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "mcp@demo.iam.gserviceaccount.com",
+		"private_key_id": "1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"token_uri":      srv.URL,
+	})
+	if err := os.WriteFile(keyFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return keyFile, grants
+}
+
+func TestServiceAccountTokenSource(t *testing.T) {
+	keyFile, grants := serviceAccountKey(t, time.Hour)
+	src, err := NewServiceAccountTokenSource(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := src.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "sa-token-1" {
+		t.Errorf("AccessToken = %q, want sa-token-1", tok.AccessToken)
+	}
+	if grants.Load() != 1 {
+		t.Errorf("token endpoint called %d times, want 1", grants.Load())
+	}
+}
+
+func TestServiceAccountTokenSourceRefresh(t *testing.T) {
+	tests := []struct {
+		name     string
+		lifetime time.Duration
+		refresh  func(*ReuseTokenSource, string)
+	}{
+		{"invalidated", time.Hour, func(s *ReuseTokenSource, token string) { s.Invalidate(token) }},
+		{"within margin", 4 * time.Minute, func(*ReuseTokenSource, string) {}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyFile, grants := serviceAccountKey(t, tt.lifetime)
+			src, err := NewServiceAccountTokenSource(keyFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reuse := NewReuseTokenSource(src, 5*time.Minute)
+			first, err := reuse.Token()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.refresh(reuse, first.AccessToken)
+			second, err := reuse.Token()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.AccessToken != "sa-token-1" || second.AccessToken != "sa-token-2" {
+				t.Errorf("tokens = %s, %s; want sa-token-1, sa-token-2", first.AccessToken, second.AccessToken)
+			}
+			if grants.Load() != 2 {
+				t.Errorf("token endpoint called %d times, want 2", grants.Load())
+			}
+		})
+	}
+}
+
+func TestCommandTokenSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		want     string
+		lifetime time.Duration
+	}{
+		{"plain", "echo plain-token", "plain-token", 10 * time.Minute},
+		{"quoted argument", `printf '%s\n' "token with spaces"`, "token with spaces", 10 * time.Minute},
+		{"pipe", "echo abc | tr a-c x-z", "xyz", 10 * time.Minute},
+		{"json", `echo '{"access_token": "json-token", "expires_in": 120}'`, "json-token", 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := NewCommandTokenSource(tt.command, 10*time.Minute).Token()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tok.AccessToken != tt.want {
+				t.Errorf("AccessToken = %q, want %q", tok.AccessToken, tt.want)
+			}
+			if d := time.Until(tok.Expiry); d > tt.lifetime || d < tt.lifetime-time.Minute {
+				t.Errorf("token expires in %v, want about %v", d, tt.lifetime)
+			}
+		})
+	}
+}
+
+func TestCommandTokenSourceError(t *testing.T) {
+	_, err := NewCommandTokenSource("echo not logged in >&2; exit 1", time.Minute).Token()
+	if err == nil || !strings.Contains(err.Error(), "not logged in") {
+		t.Errorf("Token() error = %v, want the command's stderr", err)
+	}
+	if _, err := NewCommandTokenSource("  ", time.Minute).Token(); err == nil {
+		t.Error("Token() with an empty command succeeded")
+	}
+}
+
+// countingSource hands out token-1, token-2, ... valid for lifetime.
+type countingSource struct {
+	lifetime time.Duration
+	n        atomic.Int32
+}
+
+func (s *countingSource) Token() (*oauth2.Token, error) {
+	n := s.n.Add(1)
+	return &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", n), Expiry: time.Now().Add(s.lifetime)}, nil
+}
+
+func TestReuseTokenSource(t *testing.T) {
+	src := &countingSource{lifetime: time.Hour}
+	reuse := NewReuseTokenSource(src, 5*time.Minute)
+
+	token := func() string {
+		t.Helper()
+		tok, err := reuse.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tok.AccessToken
+	}
+	if got := token(); got != "token-1" {
+		t.Fatalf("first token = %q, want token-1", got)
+	}
+	if got := token(); got != "token-1" {
+		t.Errorf("cached token = %q, want token-1", got)
+	}
+
+	// Invalidating a token that is no longer cached keeps the current one.
+	reuse.Invalidate("token-0")
+	if got := token(); got != "token-1" {
+		t.Errorf("token after invalidating another = %q, want token-1", got)
+	}
+	reuse.Invalidate("token-1")
+	if got := token(); got != "token-2" {
+		t.Errorf("token after invalidation = %q, want token-2", got)
+	}
+}
+
+func TestReuseTokenSourceRefreshesWithinMargin(t *testing.T) {
+	src := &countingSource{lifetime: 4 * time.Minute}
+	reuse := NewReuseTokenSource(src, 5*time.Minute)
+	for range 3 {
+		if _, err := reuse.Token(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := src.n.Load(); n != 3 {
+		t.Errorf("source called %d times, want 3 for tokens expiring within the margin", n)
+	}
+}
+
+func TestLoadTokenSource(t *testing.T) {
+	t.Setenv("TOKEN_COMMAND", "echo one")
+	t.Setenv("TOKEN_METADATA_URL", "http://localhost/token")
+	if _, err := loadTokenSource(); err == nil {
+		t.Error("loadTokenSource() with two sources succeeded")
+	}
+
+	t.Setenv("TOKEN_METADATA_URL", "")
+	src, err := loadTokenSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := src.(*ReuseTokenSource); !ok {
+		t.Fatalf("loadTokenSource() = %T, want *ReuseTokenSource", src)
+	}
+	tok, err := src.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(tok.Expiry); d > defaultTokenCommandTTL {
+		t.Errorf("command token expires in %v, want at most %v", d, defaultTokenCommandTTL)
+	}
+}
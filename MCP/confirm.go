@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+// confirmTTL bounds how long a confirmation token stays valid.
+const confirmTTL = 5 * time.Minute
+
+// countLimit caps how many children are counted when describing a forced
+// delete.
+const countLimit = 1000
+
+// confirmKey signs confirmation tokens. It is generated per process, so
+// tokens do not survive a restart.
+var confirmKey = func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}()
+
+// withConfirm makes a destructive tool describe what it is about to do and
+// wait for confirmation before sending the request. Clients supporting
+// elicitation are asked directly; others get a token to pass back in the
+// confirm argument of an otherwise identical call.
+func withConfirm(cfg *config.APIConfig, rt registryTool, tool models.Tool) models.Tool {
+	if !cfg.ConfirmDestructive || !rt.needsConfirmation() {
+		return tool
+	}
+	tool.Definition.InputSchema.Properties["confirm"] = map[string]any{
+		"type":        "string",
+		"description": "Confirmation token returned by a previous call with the same arguments. Only needed when the client does not support elicitation.",
+	}
+
+	c := client.New(cfg)
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]any)
+		if !ok || isDryRun(cfg, args) {
+			return handler(ctx, request)
+		}
+		token, _ := args["confirm"].(string)
+		args = maps.Clone(args)
+		delete(args, "confirm")
+		request.Params.Arguments = args
+		// The token covers the resolved path arguments, so it is accepted
+		// whether the resource was given by name or split arguments.
+		signed := maps.Clone(args)
+		delete(signed, "name")
+
+		binding := confirmBinding(ctx, config.FromContext(ctx, cfg))
+
+		if token != "" {
+			if err := checkConfirmToken(binding, rt.short, signed, token, time.Now()); err != nil {
+				return rejected(ctx, "FAILED_PRECONDITION", err.Error()), nil
+			}
+			return handler(ctx, request)
+		}
+
+		summary, err := describe(ctx, c, rt.short, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Could not inspect the resource before changing it: %v", err)), nil
+		}
+		if confirmed, asked := elicitConfirmation(ctx, summary); asked {
+			if !confirmed {
+				noteFailure(ctx, "CANCELLED")
+				return mcp.NewToolResultText("Cancelled by the user; nothing was changed."), nil
+			}
+			return handler(ctx, request)
+		}
+
+		token = confirmToken(binding, rt.short, signed, time.Now().Add(confirmTTL))
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n\nNothing has been changed yet. After the user agrees, call %s again with the same arguments and \"confirm\": %q. The token expires in %v.",
+			summary, tool.Definition.Name, token, confirmTTL)), nil
+	}
+	return tool
+}
+
+// elicitConfirmation asks the user to confirm message. asked is false when
+// the client does not support elicitation.
+func elicitConfirmation(ctx context.Context, message string) (confirmed, asked bool) {
+	srv := server.ServerFromContext(ctx)
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if srv == nil || !ok || session.GetClientCapabilities().Elicitation == nil {
+		return false, false
+	}
+	result, err := srv.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: message,
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"confirm": map[string]any{"type": "boolean", "title": "Proceed", "description": "Proceed with this change."},
+				},
+				"required": []string{"confirm"},
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("Elicitation failed, falling back to a confirmation token: %v", err)
+		return false, false
+	}
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return false, true
+	}
+	content, _ := result.Content.(map[string]any)
+	confirmed, _ = content["confirm"].(bool)
+	return confirmed, true
+}
+
+// confirmBinding identifies who a confirmation token is issued to: the MCP
+// session and the registry and credentials of the call. A token is only
+// accepted from the same session calling the same registry with the same
+// credentials, so it cannot be replayed elsewhere.
+func confirmBinding(ctx context.Context, cfg *config.APIConfig) string {
+	var session string
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		session = s.SessionID()
+	}
+	fields := []string{session, cfg.BaseURL, cfg.BearerToken, cfg.BasicAuth, cfg.APIKey}
+	if cfg.BearerToken == "" && cfg.TokenSource != nil {
+		fields = append(fields, fmt.Sprintf("%p", cfg.TokenSource))
+	}
+	return strings.Join(fields, "\x00")
+}
+
+// confirmToken returns a token authorising tool to run with args until
+// expiry, for the caller identified by binding.
+func confirmToken(binding, tool string, args map[string]any, expiry time.Time) string {
+	exp := strconv.FormatInt(expiry.Unix(), 10)
+	return exp + "." + confirmSignature(binding, tool, args, exp)
+}
+
+func checkConfirmToken(binding, tool string, args map[string]any, token string, now time.Time) error {
+	exp, sig, _ := strings.Cut(token, ".")
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || !hmac.Equal([]byte(sig), []byte(confirmSignature(binding, tool, args, exp))) {
+		return fmt.Errorf("invalid confirmation token: it must come from a call with exactly the same arguments in this session; call again without confirm to get a new one")
+	}
+	if now.Unix() > unix {
+		return fmt.Errorf("confirmation token expired; call again without confirm to get a new one")
+	}
+	return nil
+}
+
+func confirmSignature(binding, tool string, args map[string]any, exp string) string {
+	// json.Marshal sorts map keys, so equal arguments encode identically.
+	data, _ := json.Marshal(args)
+	mac := hmac.New(sha256.New, confirmKey)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s", tool, exp, binding, data)
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// describe summarises the effect of a destructive call, counting the
+// children a forced delete removes.
+func describe(ctx context.Context, c *client.Client, tool string, args map[string]any) (string, error) {
+	str := func(name string) string {
+		s, _ := args[name].(string)
+		return s
+	}
+	name := argName(args)
+	force, _ := args["force"].(bool)
+	project, location, api := str("project"), str("location"), str("api")
+
+	switch tool {
+	case "delete_api":
+		if !force {
+			return fmt.Sprintf("This deletes the API %s. It fails if the API still has versions or deployments.", name), nil
+		}
+		versions, err := count(ctx, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiVersion, string, error) {
+			page, err := c.ListApiVersions(ctx, project, location, api, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return page.Apiversions, page.Nextpagetoken, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		specs, err := count(ctx, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiSpec, string, error) {
+			page, err := c.ListApiSpecs(ctx, project, location, api, names.Wildcard, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return page.Apispecs, page.Nextpagetoken, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		deployments, err := count(ctx, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiDeployment, string, error) {
+			page, err := c.ListApiDeployments(ctx, project, location, api, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return page.Apideployments, page.Nextpagetoken, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("This permanently deletes the API %s together with its %s versions, %s specs and %s deployments.", name, versions, specs, deployments), nil
+
+	case "delete_version":
+		if !force {
+			return fmt.Sprintf("This deletes the API version %s. It fails if the version still has specs.", name), nil
+		}
+		specs, err := count(ctx, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiSpec, string, error) {
+			page, err := c.ListApiSpecs(ctx, project, location, api, str("version"), opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return page.Apispecs, page.Nextpagetoken, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("This permanently deletes the API version %s together with its %s specs.", name, specs), nil
+
+	case "delete_spec":
+		revisions, err := count(ctx, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiSpec, string, error) {
+			page, err := c.ListApiSpecRevisions(ctx, project, location, api, str("version"), str("spec"), opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return page.Apispecs, page.Nextpagetoken, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("This permanently deletes the spec %s and all %s of its revisions.", name, revisions), nil
+
+	case "delete_deployment":
+		revisions, err := count(ctx, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiDeployment, string, error) {
+			page, err := c.ListApiDeploymentRevisions(ctx, project, location, api, str("deployment"), opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return page.Apideployments, page.Nextpagetoken, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("This permanently deletes the deployment %s and all %s of its revisions.", name, revisions), nil
+
+	case "delete_spec_revision", "delete_deployment_revision":
+		return fmt.Sprintf("This permanently deletes the revision %s.", name), nil
+
+	case "delete_artifact":
+		return fmt.Sprintf("This permanently deletes the artifact %s.", name), nil
+
+	case "rollback_spec", "rollback_deployment":
+		return fmt.Sprintf("This rolls %s back to revision %s. The rollback is recorded as a new revision.", name, str("revisionId")), nil
+	}
+	return fmt.Sprintf("This runs %s on %s.", tool, name), nil
+}
+
+// count returns the number of items list yields, e.g. "12" or "more than
+// 1000".
+func count[T any](ctx context.Context, list func(context.Context, *client.ListOptions) ([]T, string, error)) (string, error) {
+	items, next, err := client.CollectPages(ctx, nil, countLimit, list)
+	if err != nil {
+		return "", err
+	}
+	if next != "" {
+		return fmt.Sprintf("more than %d", len(items)), nil
+	}
+	return strconv.Itoa(len(items)), nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/fake"
+)
+
+func TestConfirmToken(t *testing.T) {
+	args := map[string]any{"project": "demo", "location": "global", "api": "petstore", "force": true}
+	now := time.Now()
+	binding := confirmBinding(context.Background(), &config.APIConfig{BaseURL: "https://registry.example", BearerToken: "alice"})
+	token := confirmToken(binding, "delete_api", args, now.Add(confirmTTL))
+
+	other := func(cfg *config.APIConfig) string { return confirmBinding(context.Background(), cfg) }
+	tests := []struct {
+		name    string
+		binding string
+		tool    string
+		args    map[string]any
+		token   string
+		now     time.Time
+		wantErr string
+	}{
+		{"accepted", binding, "delete_api", args, token, now, ""},
+		{"accepted until expiry", binding, "delete_api", args, token, now.Add(confirmTTL), ""},
+		{"expired", binding, "delete_api", args, token, now.Add(confirmTTL + time.Second), "expired"},
+		{"other tool", binding, "delete_version", args, token, now, "invalid"},
+		{"other arguments", binding, "delete_api", map[string]any{"project": "demo", "location": "global", "api": "petstore"}, token, now, "invalid"},
+		{"other credentials", other(&config.APIConfig{BaseURL: "https://registry.example", BearerToken: "mallory"}), "delete_api", args, token, now, "invalid"},
+		{"other registry", other(&config.APIConfig{BaseURL: "https://other.example", BearerToken: "alice"}), "delete_api", args, token, now, "invalid"},
+		{"tampered expiry", binding, "delete_api", args, "9" + token, now, "invalid"},
+		{"malformed", binding, "delete_api", args, "not-a-token", now, "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConfirmToken(tt.binding, tt.tool, tt.args, tt.token, tt.now)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("checkConfirmToken: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("checkConfirmToken error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfirmBindingSession(t *testing.T) {
+	cfg := &config.APIConfig{BaseURL: "https://registry.example", BearerToken: "alice"}
+	if confirmBinding(context.Background(), cfg) == confirmBinding(context.Background(), &config.APIConfig{BaseURL: cfg.BaseURL, APIKey: "alice"}) {
+		t.Error("a bearer token and an API key with the same value give the same binding")
+	}
+	srv := createMCPServer(cfg, "STDIO", nil)
+	ctx := func(id string) context.Context {
+		return srv.WithContext(context.Background(), &fakeSession{id: id})
+	}
+	if confirmBinding(ctx("a"), cfg) == confirmBinding(ctx("b"), cfg) {
+		t.Error("two sessions get the same binding")
+	}
+}
+
+// fakeSession is a session that only has an ID.
+type fakeSession struct{ id string }
+
+func (s *fakeSession) Initialize()                                         {}
+func (s *fakeSession) Initialized() bool                                   { return true }
+func (s *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *fakeSession) SessionID() string                                   { return s.id }
+
+// confirmServer serves a fresh fake registry with destructive tools
+// requiring confirmation.
+func confirmServer(t *testing.T, opts ...client.ClientOption) (*client.Client, *fake.Registry) {
+	t.Helper()
+	up := &upstream{}
+	f := up.reset()
+	srv := httptest.NewServer(up)
+	t.Cleanup(srv.Close)
+	cfg := testConfig()
+	cfg.BaseURL = srv.URL
+	cfg.ConfirmDestructive = true
+	return serveStdio(t, cfg, opts...), f
+}
+
+func deletes(f *fake.Registry) int {
+	n := 0
+	for _, r := range f.Requests() {
+		if r.Method == http.MethodDelete {
+			n++
+		}
+	}
+	return n
+}
+
+var confirmArg = regexp.MustCompile(`"confirm": "([^"]+)"`)
+
+func TestConfirmWithToken(t *testing.T) {
+	c, f := confirmServer(t)
+	args := demo(map[string]any{"api": "petstore", "deployment": "prod"})
+
+	res := callTool(t, c, "delete_deployment", args, nil)
+	got := text(res)
+	if res.IsError || !strings.Contains(got, "permanently deletes the deployment") {
+		t.Fatalf("first call = %q, want a description of the delete", got)
+	}
+	m := confirmArg.FindStringSubmatch(got)
+	if m == nil {
+		t.Fatalf("first call = %q, want a confirm token", got)
+	}
+	if deletes(f) != 0 {
+		t.Fatal("the first call sent a delete")
+	}
+
+	wrong := demo(map[string]any{"api": "petstore", "deployment": "staging", "confirm": m[1]})
+	if res := callTool(t, c, "delete_deployment", wrong, nil); !res.IsError {
+		t.Errorf("token accepted for other arguments: %q", text(res))
+	}
+	if deletes(f) != 0 {
+		t.Fatal("a call with a token for other arguments sent a delete")
+	}
+
+	args["confirm"] = m[1]
+	if res := callTool(t, c, "delete_deployment", args, nil); res.IsError {
+		t.Fatalf("confirmed call failed: %q", text(res))
+	}
+	if deletes(f) != 1 {
+		t.Errorf("confirmed call sent %d deletes, want 1", deletes(f))
+	}
+}
+
+// elicitor answers every elicitation with action, confirming if accepted.
+type elicitor struct {
+	action mcp.ElicitationResponseAction
+	asked  int
+}
+
+func (e *elicitor) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	e.asked++
+	result := &mcp.ElicitationResult{}
+	result.Action = e.action
+	if e.action == mcp.ElicitationResponseActionAccept {
+		result.Content = map[string]any{"confirm": true}
+	}
+	return result, nil
+}
+
+func TestConfirmWithElicitation(t *testing.T) {
+	tests := []struct {
+		action  mcp.ElicitationResponseAction
+		deletes int
+		want    string
+	}{
+		{mcp.ElicitationResponseActionAccept, 1, ""},
+		{mcp.ElicitationResponseActionDecline, 0, "Cancelled by the user"},
+		{mcp.ElicitationResponseActionCancel, 0, "Cancelled by the user"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			e := &elicitor{action: tt.action}
+			c, f := confirmServer(t, client.WithElicitationHandler(e))
+			cancelled := `mcp_tool_errors_total{code="CANCELLED",tool="delete_deployment"}`
+			before := counter(t, cancelled)
+			res := callTool(t, c, "delete_deployment", demo(map[string]any{"api": "petstore", "deployment": "prod"}), nil)
+			if res.IsError {
+				t.Fatalf("call failed: %q", text(res))
+			}
+			if e.asked != 1 {
+				t.Errorf("user asked %d times, want 1", e.asked)
+			}
+			if deletes(f) != tt.deletes {
+				t.Errorf("sent %d deletes, want %d", deletes(f), tt.deletes)
+			}
+			if !strings.Contains(text(res), tt.want) {
+				t.Errorf("result = %q, want it to contain %q", text(res), tt.want)
+			}
+			// A declined change is counted as a cancelled call.
+			if got, want := counter(t, cancelled)-before, float64(1-tt.deletes); got != want {
+				t.Errorf("%s went up by %v, want %v", cancelled, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1048 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/fake"
+)
+
+// The conformance tests drive the server through an MCP client over each
+// transport, call every registry tool against a fake registry and check the
+// requests sent upstream and the results returned.
+
+const (
+	demoLocation = "/v1/projects/demo/locations/global"
+	demoAPI      = demoLocation + "/apis/petstore"
+	demoSpec     = demoAPI + "/versions/v1/specs/openapi"
+	demoSpecName = "projects/demo/locations/global/apis/petstore/versions/v1/specs/openapi"
+)
+
+var fakeClock = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// upstream is the registry behind the server under test. Every case gets a
+// freshly seeded fake.
+type upstream struct {
+	current atomic.Pointer[fake.Registry]
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.current.Load().ServeHTTP(w, r)
+}
+
+func (u *upstream) reset() *fake.Registry {
+	f := fake.New(fake.WithClock(func() time.Time { return fakeClock }))
+	f.Seed("demo", "global")
+	u.current.Store(f)
+	return f
+}
+
+// testConfig is the server configuration used by the conformance tests. The
+// registry URL is only set for STDIO; over HTTP it comes from the headers.
+func testConfig() *config.APIConfig {
+	return &config.APIConfig{
+		DefaultLocation: config.DefaultLocationID,
+		Timeout:         10 * time.Second,
+		Retry:           config.RetryPolicy{MaxAttempts: 1},
+		WatchInterval:   time.Minute,
+		ToolNaming:      config.ToolNamingShort,
+	}
+}
+
+// connectStdio serves the test configuration for the registry at baseURL.
+func connectStdio(t *testing.T, baseURL string) *client.Client {
+	cfg := testConfig()
+	cfg.BaseURL = baseURL
+	return serveStdio(t, cfg)
+}
+
+// serveStdio serves cfg over a pair of pipes, as a client process would talk
+// to the server over stdin and stdout, and returns an initialised client
+// created with opts.
+func serveStdio(t *testing.T, cfg *config.APIConfig, opts ...client.ClientOption) *client.Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	toServer, fromClient := io.Pipe()
+	toClient, fromServer := io.Pipe()
+	stdio := server.NewStdioServer(createMCPServer(cfg, "STDIO", nil))
+	stdio.SetErrorLogger(log.New(io.Discard, "", 0))
+	go stdio.Listen(ctx, toServer, fromServer)
+
+	c := client.NewClient(transport.NewIO(toClient, fromClient, io.NopCloser(strings.NewReader(""))), opts...)
+	t.Cleanup(func() {
+		c.Close()
+		cancel()
+		fromServer.Close()
+	})
+	initialize(t, c)
+	return c
+}
+
+// connectHTTP serves the HTTP mode handler and connects with the registry
+// URL in the headers.
+func connectHTTP(t *testing.T, baseURL string) *client.Client {
+	mux, streamable := newHTTPHandler(testConfig(), "HTTP")
+	srv := httptest.NewServer(mux)
+	tr, err := transport.NewStreamableHTTP(srv.URL+"/mcp", transport.WithHTTPHeaders(map[string]string{
+		"API_BASE_URL": baseURL,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewClient(tr)
+	t.Cleanup(func() {
+		c.Close()
+		streamable.Shutdown(context.Background())
+		srv.Close()
+	})
+	initialize(t, c)
+	return c
+}
+
+func initialize(t *testing.T, c *client.Client) {
+	t.Helper()
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	req := mcp.InitializeRequest{}
+	req.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	req.Params.ClientInfo = mcp.Implementation{Name: "conformance", Version: "1"}
+	if _, err := c.Initialize(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// wantRequest is a request the registry must receive. A nil query or body
+// must be empty.
+type wantRequest struct {
+	method string
+	path   string
+	query  url.Values
+	body   string // JSON
+}
+
+// toolCase calls a tool once and checks what was sent and returned.
+type toolCase struct {
+	name     string
+	tool     string
+	args     map[string]any
+	requests []wantRequest // In order; none means nothing may be sent
+	isError  bool
+	// invalid marks a call with invalid arguments. It must fail, either
+	// before anything is sent or with INVALID_ARGUMENT from the registry.
+	invalid bool
+	checks  []check
+}
+
+type check func(t *testing.T, res *mcp.CallToolResult)
+
+func get(path string) wantRequest {
+	return wantRequest{method: http.MethodGet, path: path}
+}
+
+func (w wantRequest) withQuery(kv ...string) wantRequest {
+	w.query = url.Values{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		w.query.Add(kv[i], kv[i+1])
+	}
+	return w
+}
+
+func send(method, path, body string) wantRequest {
+	return wantRequest{method: method, path: path, body: body}
+}
+
+// text returns the text contents of res joined by newlines.
+func text(res *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range res.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			parts = append(parts, tc.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// object decodes the first text content of res as a JSON object.
+func object(t *testing.T, res *mcp.CallToolResult) map[string]any {
+	t.Helper()
+	var out map[string]any
+	tc, ok := res.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("result content is %T, want text", res.Content[0])
+	}
+	if err := json.Unmarshal([]byte(tc.Text), &out); err != nil {
+		t.Fatalf("result is not a JSON object: %v\n%s", err, tc.Text)
+	}
+	return out
+}
+
+// field checks a top-level field of a JSON result.
+func field(key string, want any) check {
+	return func(t *testing.T, res *mcp.CallToolResult) {
+		t.Helper()
+		if got := object(t, res)[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("result %s = %#v, want %#v", key, got, want)
+		}
+	}
+}
+
+// items checks the number of items in a list result and its resource names.
+func items(key string, names ...string) check {
+	return func(t *testing.T, res *mcp.CallToolResult) {
+		t.Helper()
+		list, _ := object(t, res)[key].([]any)
+		var got []string
+		for _, item := range list {
+			name, _ := item.(map[string]any)["name"].(string)
+			got = append(got, name)
+		}
+		if !slices.Equal(got, names) {
+			t.Errorf("result %s names = %q, want %q", key, got, names)
+		}
+	}
+}
+
+// contains checks that the text of the result contains s.
+func contains(s string) check {
+	return func(t *testing.T, res *mcp.CallToolResult) {
+		t.Helper()
+		if got := text(res); !strings.Contains(got, s) {
+			t.Errorf("result %q does not contain %q", got, s)
+		}
+	}
+}
+
+// status checks the google.rpc code of an upstream error result, carried as
+// structured content.
+func status(code string) check {
+	return func(t *testing.T, res *mcp.CallToolResult) {
+		t.Helper()
+		data, _ := json.Marshal(res.StructuredContent)
+		var s struct {
+			Status string `json:"status"`
+		}
+		json.Unmarshal(data, &s)
+		if s.Status != code {
+			t.Errorf("error status = %q, want %q (structured content %s)", s.Status, code, data)
+		}
+	}
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// demo returns args with the seeded project added.
+func demo(args map[string]any) map[string]any {
+	args["project"] = "demo"
+	return args
+}
+
+var toolCases = []toolCase{
+	// APIs
+	{
+		name:     "list_apis",
+		tool:     "list_apis",
+		args:     demo(map[string]any{}),
+		requests: []wantRequest{get(demoLocation + "/apis")},
+		checks:   []check{items("apis", "projects/demo/locations/global/apis/payments", "projects/demo/locations/global/apis/petstore")},
+	},
+	{
+		name:     "list_apis filter and page",
+		tool:     "list_apis",
+		args:     demo(map[string]any{"filter": `labels.team == "pets"`, "pageSize": 1}),
+		requests: []wantRequest{get(demoLocation+"/apis").withQuery("filter", `labels.team == "pets"`, "pageSize", "1")},
+		checks:   []check{items("apis", "projects/demo/locations/global/apis/petstore")},
+	},
+	{
+		name: "list_apis all pages",
+		tool: "list_apis",
+		args: demo(map[string]any{"pageSize": 1, "all": true}),
+		requests: []wantRequest{
+			get(demoLocation+"/apis").withQuery("pageSize", "1"),
+			get(demoLocation+"/apis").withQuery("pageSize", "1", "pageToken", "MQ"),
+		},
+		checks: []check{items("apis", "projects/demo/locations/global/apis/payments", "projects/demo/locations/global/apis/petstore")},
+	},
+	{
+		name:     "list_apis maxItems truncated",
+		tool:     "list_apis",
+		args:     demo(map[string]any{"maxItems": 1}),
+		requests: []wantRequest{get(demoLocation+"/apis").withQuery("pageSize", "1")},
+		checks: []check{
+			items("apis", "projects/demo/locations/global/apis/payments"),
+			field("nextPageToken", "MQ"),
+			contains(`Result truncated after 1 items; more are available. Call again with pageToken="MQ" (and maxItems) to continue.`),
+		},
+	},
+	{
+		name:     "list_apis maxItems resumed",
+		tool:     "list_apis",
+		args:     demo(map[string]any{"maxItems": 1, "pageToken": "MQ"}),
+		requests: []wantRequest{get(demoLocation+"/apis").withQuery("pageSize", "1", "pageToken", "MQ")},
+		checks:   []check{items("apis", "projects/demo/locations/global/apis/petstore"), field("nextPageToken", nil)},
+	},
+	{
+		name:     "list_apis invalid filter",
+		tool:     "list_apis",
+		args:     demo(map[string]any{"filter": "labels.team =="}),
+		requests: []wantRequest{get(demoLocation+"/apis").withQuery("filter", "labels.team ==")},
+		invalid:  true,
+	},
+	{
+		name:     "get_api",
+		tool:     "get_api",
+		args:     demo(map[string]any{"api": "petstore"}),
+		requests: []wantRequest{get(demoAPI)},
+		checks:   []check{field("name", "projects/demo/locations/global/apis/petstore"), field("displayName", "Swagger Petstore")},
+	},
+	{
+		name:     "get_api by name",
+		tool:     "get_api",
+		args:     map[string]any{"name": "projects/demo/locations/global/apis/payments"},
+		requests: []wantRequest{get(demoLocation + "/apis/payments")},
+		checks:   []check{field("availability", "PREVIEW")},
+	},
+	{
+		name:     "get_api not found",
+		tool:     "get_api",
+		args:     demo(map[string]any{"api": "missing"}),
+		requests: []wantRequest{get(demoLocation + "/apis/missing")},
+		isError:  true,
+		checks:   []check{status("NOT_FOUND")},
+	},
+	{
+		name:    "get_api invalid ID",
+		tool:    "get_api",
+		args:    demo(map[string]any{"api": "Not_An_ID"}),
+		invalid: true,
+		checks:  []check{contains("invalid api ID")},
+	},
+	{
+		name:    "get_api missing argument",
+		tool:    "get_api",
+		args:    demo(map[string]any{}),
+		invalid: true,
+		checks:  []check{contains("api")},
+	},
+	{
+		name:     "create_api",
+		tool:     "create_api",
+		args:     demo(map[string]any{"apiId": "orders", "displayName": "Orders", "labels": map[string]any{"team": "sales"}}),
+		requests: []wantRequest{send(http.MethodPost, demoLocation+"/apis", `{"displayName":"Orders","labels":{"team":"sales"}}`).withQuery("apiId", "orders")},
+		checks:   []check{field("name", "projects/demo/locations/global/apis/orders"), field("createTime", "2026-01-02T03:04:05Z")},
+	},
+	{
+		name:     "create_api exists",
+		tool:     "create_api",
+		args:     demo(map[string]any{"apiId": "petstore"}),
+		requests: []wantRequest{send(http.MethodPost, demoLocation+"/apis", `{}`).withQuery("apiId", "petstore")},
+		isError:  true,
+		checks:   []check{status("ALREADY_EXISTS")},
+	},
+	{
+		name:    "create_api invalid ID",
+		tool:    "create_api",
+		args:    demo(map[string]any{"apiId": "-orders"}),
+		invalid: true,
+		checks:  []check{contains("invalid api ID")},
+	},
+	{
+		name:     "update_api",
+		tool:     "update_api",
+		args:     demo(map[string]any{"api": "petstore", "description": "Pets.", "updateMask": "description"}),
+		requests: []wantRequest{send(http.MethodPatch, demoAPI, `{"description":"Pets."}`).withQuery("updateMask", "description")},
+		checks:   []check{field("description", "Pets."), field("displayName", "Swagger Petstore")},
+	},
+	{
+		name:     "update_api invalid mask",
+		tool:     "update_api",
+		args:     demo(map[string]any{"api": "petstore", "description": "Pets.", "updateMask": "colour"}),
+		requests: []wantRequest{send(http.MethodPatch, demoAPI, `{"description":"Pets."}`).withQuery("updateMask", "colour")},
+		invalid:  true,
+	},
+	{
+		name:     "update_api allow missing",
+		tool:     "update_api",
+		args:     demo(map[string]any{"api": "orders", "displayName": "Orders", "allowMissing": true}),
+		requests: []wantRequest{send(http.MethodPatch, demoLocation+"/apis/orders", `{"displayName":"Orders"}`).withQuery("allowMissing", "true")},
+		checks:   []check{field("name", "projects/demo/locations/global/apis/orders")},
+	},
+	{
+		name:     "delete_api",
+		tool:     "delete_api",
+		args:     demo(map[string]any{"api": "payments", "force": true}),
+		requests: []wantRequest{send(http.MethodDelete, demoLocation+"/apis/payments", "").withQuery("force", "true")},
+	},
+	{
+		name:    "delete_api invalid ID",
+		tool:    "delete_api",
+		args:    demo(map[string]any{"api": "Petstore"}),
+		invalid: true,
+		checks:  []check{contains("invalid api ID")},
+	},
+	{
+		name:     "delete_api with children",
+		tool:     "delete_api",
+		args:     demo(map[string]any{"api": "petstore"}),
+		requests: []wantRequest{send(http.MethodDelete, demoAPI, "")},
+		isError:  true,
+		checks:   []check{status("FAILED_PRECONDITION")},
+	},
+
+	// Versions
+	{
+		name:     "list_versions",
+		tool:     "list_versions",
+		args:     demo(map[string]any{"api": "-"}),
+		requests: []wantRequest{get(demoLocation + "/apis/-/versions")},
+		checks:   []check{items("apiVersions", "projects/demo/locations/global/apis/payments/versions/v1alpha1", "projects/demo/locations/global/apis/petstore/versions/v1")},
+	},
+	{
+		name:     "list_versions invalid page size",
+		tool:     "list_versions",
+		args:     demo(map[string]any{"api": "petstore", "pageSize": -1}),
+		requests: []wantRequest{get(demoAPI+"/versions").withQuery("pageSize", "-1")},
+		invalid:  true,
+	},
+	{
+		name:     "list_versions of missing API",
+		tool:     "list_versions",
+		args:     demo(map[string]any{"api": "missing"}),
+		requests: []wantRequest{get(demoLocation + "/apis/missing/versions")},
+		isError:  true,
+		checks:   []check{status("NOT_FOUND")},
+	},
+	{
+		name:     "get_version",
+		tool:     "get_version",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1"}),
+		requests: []wantRequest{get(demoAPI + "/versions/v1")},
+		checks:   []check{field("state", "PRODUCTION")},
+	},
+	{
+		name:    "get_version invalid ID",
+		tool:    "get_version",
+		args:    demo(map[string]any{"api": "petstore", "version": "v1.0"}),
+		invalid: true,
+		checks:  []check{contains("invalid version ID")},
+	},
+	{
+		name:     "create_version",
+		tool:     "create_version",
+		args:     demo(map[string]any{"api": "petstore", "apiVersionId": "v2", "state": "DESIGN"}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/versions", `{"state":"DESIGN"}`).withQuery("apiVersionId", "v2")},
+		checks:   []check{field("name", "projects/demo/locations/global/apis/petstore/versions/v2")},
+	},
+	{
+		name:    "create_version invalid ID",
+		tool:    "create_version",
+		args:    demo(map[string]any{"api": "petstore", "apiVersionId": "V2"}),
+		invalid: true,
+		checks:  []check{contains("invalid version ID")},
+	},
+	{
+		name:     "create_version in missing API",
+		tool:     "create_version",
+		args:     demo(map[string]any{"api": "missing", "apiVersionId": "v1"}),
+		requests: []wantRequest{send(http.MethodPost, demoLocation+"/apis/missing/versions", `{}`).withQuery("apiVersionId", "v1")},
+		isError:  true,
+		checks:   []check{status("NOT_FOUND")},
+	},
+	{
+		name:     "update_version",
+		tool:     "update_version",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "state": "DEPRECATED"}),
+		requests: []wantRequest{send(http.MethodPatch, demoAPI+"/versions/v1", `{"state":"DEPRECATED"}`)},
+		checks:   []check{field("state", "DEPRECATED")},
+	},
+	{
+		name:     "update_version invalid mask",
+		tool:     "update_version",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "state": "DEPRECATED", "updateMask": "colour"}),
+		requests: []wantRequest{send(http.MethodPatch, demoAPI+"/versions/v1", `{"state":"DEPRECATED"}`).withQuery("updateMask", "colour")},
+		invalid:  true,
+	},
+	{
+		name:     "delete_version",
+		tool:     "delete_version",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "force": true}),
+		requests: []wantRequest{send(http.MethodDelete, demoAPI+"/versions/v1", "").withQuery("force", "true")},
+	},
+	{
+		name:    "delete_version missing argument",
+		tool:    "delete_version",
+		args:    demo(map[string]any{"api": "petstore"}),
+		invalid: true,
+		checks:  []check{contains("version")},
+	},
+
+	// Specs
+	{
+		name:     "list_specs",
+		tool:     "list_specs",
+		args:     demo(map[string]any{"api": "-", "version": "-"}),
+		requests: []wantRequest{get(demoLocation + "/apis/-/versions/-/specs")},
+		checks:   []check{items("apiSpecs", demoSpecName)},
+	},
+	{
+		name:     "list_specs invalid filter",
+		tool:     "list_specs",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "filter": "mimeType.reverse()"}),
+		requests: []wantRequest{get(demoAPI+"/versions/v1/specs").withQuery("filter", "mimeType.reverse()")},
+		invalid:  true,
+	},
+	{
+		name:     "get_spec",
+		tool:     "get_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi"}),
+		requests: []wantRequest{get(demoSpec)},
+		checks:   []check{field("revisionId", "00000002"), field("mimeType", "application/x.openapi+yaml;version=3")},
+	},
+	{
+		name:     "get_spec revision by tag",
+		tool:     "get_spec",
+		args:     map[string]any{"name": demoSpecName + "@stable"},
+		requests: []wantRequest{get(demoSpec + "@stable")},
+		checks:   []check{field("name", demoSpecName+"@00000002")},
+	},
+	{
+		name:    "get_spec invalid name",
+		tool:    "get_spec",
+		args:    map[string]any{"name": "projects/demo/apis/petstore"},
+		invalid: true,
+		checks:  []check{contains("invalid resource name")},
+	},
+	{
+		name:     "get_spec_contents",
+		tool:     "get_spec_contents",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi@00000001"}),
+		requests: []wantRequest{get(demoSpec + "@00000001:getContents")},
+		checks:   []check{contains("title: Swagger Petstore"), contains("version: 1.0.0"), contains("application/x.openapi+yaml")},
+	},
+	{
+		name:    "get_spec_contents invalid revision",
+		tool:    "get_spec_contents",
+		args:    demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi@latest!"}),
+		invalid: true,
+		checks:  []check{contains("invalid spec revision ID")},
+	},
+	{
+		name:     "get_spec_contents not found",
+		tool:     "get_spec_contents",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "graphql"}),
+		requests: []wantRequest{get(demoAPI + "/versions/v1/specs/graphql:getContents")},
+		isError:  true,
+		checks:   []check{status("NOT_FOUND")},
+	},
+	{
+		name: "create_spec",
+		tool: "create_spec",
+		args: demo(map[string]any{"api": "petstore", "version": "v1", "apiSpecId": "proto", "mimeType": "text/plain", "contents": b64("syntax = \"proto3\";")}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/versions/v1/specs",
+			`{"mimeType":"text/plain","contents":"`+b64("syntax = \"proto3\";")+`"}`).withQuery("apiSpecId", "proto")},
+		checks: []check{field("sizeBytes", 18.0), field("revisionId", "00000005")},
+	},
+	{
+		name:     "create_spec contents not base64",
+		tool:     "create_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "apiSpecId": "proto", "contents": "syntax = proto3"}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/versions/v1/specs", `{"contents":"syntax = proto3"}`).withQuery("apiSpecId", "proto")},
+		invalid:  true,
+	},
+	{
+		name:     "update_spec new revision",
+		tool:     "update_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "contents": b64("openapi: 3.1.0")}),
+		requests: []wantRequest{send(http.MethodPatch, demoSpec, `{"contents":"`+b64("openapi: 3.1.0")+`"}`)},
+		checks:   []check{field("revisionId", "00000005"), field("filename", "openapi.yaml")},
+	},
+	{
+		name:     "update_spec metadata",
+		tool:     "update_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "description": "Petstore v1", "updateMask": "description"}),
+		requests: []wantRequest{send(http.MethodPatch, demoSpec, `{"description":"Petstore v1"}`).withQuery("updateMask", "description")},
+		checks:   []check{field("revisionId", "00000002"), field("description", "Petstore v1")},
+	},
+	{
+		name:     "update_spec contents not base64",
+		tool:     "update_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "contents": "openapi: 3.1.0"}),
+		requests: []wantRequest{send(http.MethodPatch, demoSpec, `{"contents":"openapi: 3.1.0"}`)},
+		invalid:  true,
+	},
+	{
+		name:     "delete_spec",
+		tool:     "delete_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi"}),
+		requests: []wantRequest{send(http.MethodDelete, demoSpec, "")},
+	},
+	{
+		name:    "delete_spec invalid ID",
+		tool:    "delete_spec",
+		args:    demo(map[string]any{"api": "petstore", "version": "v1", "spec": "open_api"}),
+		invalid: true,
+		checks:  []check{contains("invalid spec ID")},
+	},
+	{
+		name:     "list_spec_revisions",
+		tool:     "list_spec_revisions",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi"}),
+		requests: []wantRequest{get(demoSpec + ":listRevisions")},
+		checks:   []check{items("apiSpecs", demoSpecName+"@00000002", demoSpecName+"@00000001")},
+	},
+	{
+		name:     "list_spec_revisions invalid page token",
+		tool:     "list_spec_revisions",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "pageToken": "nope"}),
+		requests: []wantRequest{get(demoSpec+":listRevisions").withQuery("pageToken", "nope")},
+		invalid:  true,
+	},
+	{
+		name:     "tag_spec_revision",
+		tool:     "tag_spec_revision",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi@00000001", "tag": "legacy"}),
+		requests: []wantRequest{send(http.MethodPost, demoSpec+"@00000001:tagRevision", `{"name":"","tag":"legacy"}`)},
+		checks:   []check{field("name", demoSpecName+"@00000001")},
+	},
+	{
+		name:     "tag_spec_revision invalid tag",
+		tool:     "tag_spec_revision",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "tag": "v1"}),
+		requests: []wantRequest{send(http.MethodPost, demoSpec+":tagRevision", `{"name":"","tag":"v1"}`)},
+		invalid:  true,
+	},
+	{
+		name:     "rollback_spec",
+		tool:     "rollback_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "revisionId": "00000001"}),
+		requests: []wantRequest{send(http.MethodPost, demoSpec+":rollback", `{"name":"","revisionId":"00000001"}`)},
+		checks:   []check{field("name", demoSpecName+"@00000005")},
+	},
+	{
+		name:     "rollback_spec unknown revision",
+		tool:     "rollback_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "revisionId": "ffffffff"}),
+		requests: []wantRequest{send(http.MethodPost, demoSpec+":rollback", `{"name":"","revisionId":"ffffffff"}`)},
+		isError:  true,
+		checks:   []check{status("NOT_FOUND")},
+	},
+	{
+		name:     "rollback_spec missing revision",
+		tool:     "rollback_spec",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "revisionId": ""}),
+		requests: []wantRequest{send(http.MethodPost, demoSpec+":rollback", `{"name":"","revisionId":""}`)},
+		invalid:  true,
+	},
+	{
+		name:     "delete_spec_revision",
+		tool:     "delete_spec_revision",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi@stable"}),
+		requests: []wantRequest{send(http.MethodDelete, demoSpec+"@stable:deleteRevision", "")},
+		checks:   []check{field("revisionId", "00000001")},
+	},
+	{
+		name:     "delete_spec_revision without revision",
+		tool:     "delete_spec_revision",
+		args:     demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi"}),
+		requests: []wantRequest{send(http.MethodDelete, demoSpec+":deleteRevision", "")},
+		invalid:  true,
+	},
+
+	// Deployments
+	{
+		name:     "list_deployments",
+		tool:     "list_deployments",
+		args:     demo(map[string]any{"api": "petstore", "filter": "labels.env == 'prod'"}),
+		requests: []wantRequest{get(demoAPI+"/deployments").withQuery("filter", "labels.env == 'prod'")},
+		checks:   []check{items("apiDeployments", "projects/demo/locations/global/apis/petstore/deployments/prod")},
+	},
+	{
+		name:     "list_deployments invalid filter",
+		tool:     "list_deployments",
+		args:     demo(map[string]any{"api": "petstore", "filter": "labels.env = 'prod'"}),
+		requests: []wantRequest{get(demoAPI+"/deployments").withQuery("filter", "labels.env = 'prod'")},
+		invalid:  true,
+	},
+	{
+		name:     "get_deployment",
+		tool:     "get_deployment",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod"}),
+		requests: []wantRequest{get(demoAPI + "/deployments/prod")},
+		checks:   []check{field("revisionId", "00000004"), field("apiSpecRevision", demoSpecName+"@00000002")},
+	},
+	{
+		name:    "get_deployment invalid ID",
+		tool:    "get_deployment",
+		args:    demo(map[string]any{"api": "petstore", "deployment": "prod-"}),
+		invalid: true,
+		checks:  []check{contains("invalid deployment ID")},
+	},
+	{
+		name:     "create_deployment",
+		tool:     "create_deployment",
+		args:     demo(map[string]any{"api": "petstore", "apiDeploymentId": "staging", "endpointUri": "https://staging.example.com"}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/deployments", `{"endpointUri":"https://staging.example.com"}`).withQuery("apiDeploymentId", "staging")},
+		checks:   []check{field("revisionId", "00000005")},
+	},
+	{
+		name:     "create_deployment invalid label",
+		tool:     "create_deployment",
+		args:     demo(map[string]any{"api": "petstore", "apiDeploymentId": "staging", "labels": map[string]any{"env": 1}}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/deployments", `{"labels":{"env":1}}`).withQuery("apiDeploymentId", "staging")},
+		invalid:  true,
+	},
+	{
+		name:     "update_deployment",
+		tool:     "update_deployment",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod", "endpointUri": "https://petstore.example.com/v2"}),
+		requests: []wantRequest{send(http.MethodPatch, demoAPI+"/deployments/prod", `{"endpointUri":"https://petstore.example.com/v2"}`)},
+		checks:   []check{field("revisionId", "00000005")},
+	},
+	{
+		name:     "update_deployment invalid mask",
+		tool:     "update_deployment",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod", "endpointUri": "https://petstore.example.com/v2", "updateMask": "endpoint"}),
+		requests: []wantRequest{send(http.MethodPatch, demoAPI+"/deployments/prod", `{"endpointUri":"https://petstore.example.com/v2"}`).withQuery("updateMask", "endpoint")},
+		invalid:  true,
+	},
+	{
+		name:     "delete_deployment",
+		tool:     "delete_deployment",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod"}),
+		requests: []wantRequest{send(http.MethodDelete, demoAPI+"/deployments/prod", "")},
+	},
+	{
+		name:    "delete_deployment invalid ID",
+		tool:    "delete_deployment",
+		args:    demo(map[string]any{"api": "petstore", "deployment": "Prod"}),
+		invalid: true,
+		checks:  []check{contains("invalid deployment ID")},
+	},
+	{
+		name:     "list_deployment_revisions",
+		tool:     "list_deployment_revisions",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod", "pageSize": 1}),
+		requests: []wantRequest{get(demoAPI+"/deployments/prod:listRevisions").withQuery("pageSize", "1")},
+		checks:   []check{items("apiDeployments", "projects/demo/locations/global/apis/petstore/deployments/prod@00000004"), field("nextPageToken", "MQ")},
+	},
+	{
+		name:     "list_deployment_revisions invalid page size",
+		tool:     "list_deployment_revisions",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod", "pageSize": -5}),
+		requests: []wantRequest{get(demoAPI+"/deployments/prod:listRevisions").withQuery("pageSize", "-5")},
+		invalid:  true,
+	},
+	{
+		name:     "tag_deployment_revision",
+		tool:     "tag_deployment_revision",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod@00000003", "tag": "launch"}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/deployments/prod@00000003:tagRevision", `{"name":"","tag":"launch"}`)},
+		checks:   []check{field("revisionId", "00000003")},
+	},
+	{
+		name:     "tag_deployment_revision invalid tag",
+		tool:     "tag_deployment_revision",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod", "tag": "Launch Day"}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/deployments/prod:tagRevision", `{"name":"","tag":"Launch Day"}`)},
+		invalid:  true,
+	},
+	{
+		name:     "rollback_deployment",
+		tool:     "rollback_deployment",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod", "revisionId": "00000003"}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/deployments/prod:rollback", `{"name":"","revisionId":"00000003"}`)},
+		checks:   []check{field("apiSpecRevision", demoSpecName+"@00000001")},
+	},
+	{
+		name:     "rollback_deployment missing revision",
+		tool:     "rollback_deployment",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod", "revisionId": ""}),
+		requests: []wantRequest{send(http.MethodPost, demoAPI+"/deployments/prod:rollback", `{"name":"","revisionId":""}`)},
+		invalid:  true,
+	},
+	{
+		name:     "delete_deployment_revision",
+		tool:     "delete_deployment_revision",
+		args:     demo(map[string]any{"api": "petstore", "deployment": "prod@00000004"}),
+		requests: []wantRequest{send(http.MethodDelete, demoAPI+"/deployments/prod@00000004:deleteRevision", "")},
+		checks:   []check{field("revisionId", "00000003")},
+	},
+	{
+		name:    "delete_deployment_revision invalid revision",
+		tool:    "delete_deployment_revision",
+		args:    demo(map[string]any{"api": "petstore", "deployment": "prod@NOPE"}),
+		invalid: true,
+		checks:  []check{contains("invalid deployment revision ID")},
+	},
+
+	// Artifacts
+	{
+		name:     "list_artifacts",
+		tool:     "list_artifacts",
+		args:     demo(map[string]any{}),
+		requests: []wantRequest{get(demoLocation + "/artifacts")},
+		checks:   []check{items("artifacts", "projects/demo/locations/global/artifacts/style-guide")},
+	},
+	{
+		name:     "list_artifacts invalid filter",
+		tool:     "list_artifacts",
+		args:     demo(map[string]any{"filter": `mimeType == "text/markdown`}),
+		requests: []wantRequest{get(demoLocation+"/artifacts").withQuery("filter", `mimeType == "text/markdown`)},
+		invalid:  true,
+	},
+	{
+		name:     "get_artifact",
+		tool:     "get_artifact",
+		args:     demo(map[string]any{"artifact": "style-guide"}),
+		requests: []wantRequest{get(demoLocation + "/artifacts/style-guide")},
+		checks:   []check{field("mimeType", "text/markdown"), field("sizeBytes", 67.0)},
+	},
+	{
+		name:    "get_artifact invalid ID",
+		tool:    "get_artifact",
+		args:    demo(map[string]any{"artifact": "style guide"}),
+		invalid: true,
+		checks:  []check{contains("invalid artifact ID")},
+	},
+	{
+		name:     "get_artifact_contents",
+		tool:     "get_artifact_contents",
+		args:     demo(map[string]any{"artifact": "style-guide"}),
+		requests: []wantRequest{get(demoLocation + "/artifacts/style-guide:getContents")},
+		checks:   []check{contains("# API style guide"), contains("67 bytes of text/markdown")},
+	},
+	{
+		name:    "get_artifact_contents missing argument",
+		tool:    "get_artifact_contents",
+		args:    demo(map[string]any{}),
+		invalid: true,
+		checks:  []check{contains("artifact")},
+	},
+	{
+		name:     "create_artifact",
+		tool:     "create_artifact",
+		args:     demo(map[string]any{"artifactId": "owners", "mimeType": "application/json", "contents": b64(`{"owner":"pets"}`)}),
+		requests: []wantRequest{send(http.MethodPost, demoLocation+"/artifacts", `{"mimeType":"application/json","contents":"`+b64(`{"owner":"pets"}`)+`"}`).withQuery("artifactId", "owners")},
+		checks:   []check{field("name", "projects/demo/locations/global/artifacts/owners"), field("sizeBytes", 16.0)},
+	},
+	{
+		name:     "create_artifact contents not base64",
+		tool:     "create_artifact",
+		args:     demo(map[string]any{"artifactId": "owners", "contents": `{"owner":"pets"}`}),
+		requests: []wantRequest{send(http.MethodPost, demoLocation+"/artifacts", `{"contents":"{\"owner\":\"pets\"}"}`).withQuery("artifactId", "owners")},
+		invalid:  true,
+	},
+	{
+		name:     "replace_artifact",
+		tool:     "replace_artifact",
+		args:     demo(map[string]any{"artifact": "style-guide", "mimeType": "text/plain", "contents": b64("Be consistent.")}),
+		requests: []wantRequest{send(http.MethodPut, demoLocation+"/artifacts/style-guide", `{"mimeType":"text/plain","contents":"`+b64("Be consistent.")+`"}`)},
+		checks:   []check{field("mimeType", "text/plain"), field("sizeBytes", 14.0)},
+	},
+	{
+		name:     "replace_artifact not found",
+		tool:     "replace_artifact",
+		args:     demo(map[string]any{"artifact": "missing", "contents": b64("x")}),
+		requests: []wantRequest{send(http.MethodPut, demoLocation+"/artifacts/missing", `{"contents":"`+b64("x")+`"}`)},
+		isError:  true,
+		checks:   []check{status("NOT_FOUND")},
+	},
+	{
+		name:     "replace_artifact contents not base64",
+		tool:     "replace_artifact",
+		args:     demo(map[string]any{"artifact": "style-guide", "contents": "Be consistent."}),
+		requests: []wantRequest{send(http.MethodPut, demoLocation+"/artifacts/style-guide", `{"contents":"Be consistent."}`)},
+		invalid:  true,
+	},
+	{
+		name:     "delete_artifact",
+		tool:     "delete_artifact",
+		args:     demo(map[string]any{"artifact": "style-guide"}),
+		requests: []wantRequest{send(http.MethodDelete, demoLocation+"/artifacts/style-guide", "")},
+	},
+	{
+		name:    "delete_artifact invalid ID",
+		tool:    "delete_artifact",
+		args:    demo(map[string]any{"artifact": "-style-guide"}),
+		invalid: true,
+		checks:  []check{contains("invalid artifact ID")},
+	},
+}
+
+func TestConformance(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	up := &upstream{}
+	registry := httptest.NewServer(up)
+	defer registry.Close()
+
+	transports := []struct {
+		name    string
+		connect func(*testing.T, string) *client.Client
+	}{
+		{"stdio", connectStdio},
+		{"http", connectHTTP},
+	}
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			up.reset()
+			c := tr.connect(t, registry.URL)
+			t.Run("tools/list", func(t *testing.T) {
+				testToolList(t, c)
+			})
+			for _, tc := range toolCases {
+				t.Run(tc.name, func(t *testing.T) {
+					f := up.reset()
+					tc.run(t, c, f)
+				})
+			}
+		})
+	}
+}
+
+// TestConformanceCoverage checks that every registered tool has a case that
+// succeeds and one with invalid arguments.
+func TestConformanceCoverage(t *testing.T) {
+	succeeds, invalid := map[string]bool{}, map[string]bool{}
+	for _, tc := range toolCases {
+		switch {
+		case tc.invalid:
+			invalid[tc.tool] = true
+		case !tc.isError:
+			succeeds[tc.tool] = true
+		}
+	}
+	cfg := testConfig()
+	for _, tool := range GetAll(cfg) {
+		if !succeeds[tool.Definition.Name] {
+			t.Errorf("no successful conformance case for %s", tool.Definition.Name)
+		}
+		if !invalid[tool.Definition.Name] {
+			t.Errorf("no conformance case with invalid arguments for %s", tool.Definition.Name)
+		}
+	}
+}
+
+// testToolList checks that tools/list returns the tools of GetAll with their
+// schemas and annotations.
+func testToolList(t *testing.T, c *client.Client) {
+	res, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := map[string]mcp.Tool{}
+	for _, tool := range res.Tools {
+		listed[tool.Name] = tool
+	}
+	want := GetAll(testConfig())
+	if len(res.Tools) != len(want) {
+		t.Errorf("tools/list returned %d tools, want %d", len(res.Tools), len(want))
+	}
+	for _, tool := range want {
+		got, ok := listed[tool.Definition.Name]
+		if !ok {
+			t.Errorf("tools/list is missing %s", tool.Definition.Name)
+			continue
+		}
+		if got.Description == "" || got.InputSchema.Type != "object" {
+			t.Errorf("%s: missing description or object input schema", got.Name)
+		}
+		if got.Annotations.ReadOnlyHint == nil || *got.Annotations.ReadOnlyHint != *tool.Definition.Annotations.ReadOnlyHint {
+			t.Errorf("%s: readOnlyHint = %v, want %v", got.Name, got.Annotations.ReadOnlyHint, *tool.Definition.Annotations.ReadOnlyHint)
+		}
+		if !slices.Equal(slices.Sorted(slices.Values(got.InputSchema.Required)), slices.Sorted(slices.Values(tool.Definition.InputSchema.Required))) {
+			t.Errorf("%s: required = %q, want %q", got.Name, got.InputSchema.Required, tool.Definition.InputSchema.Required)
+		}
+	}
+}
+
+func (tc toolCase) run(t *testing.T, c *client.Client, f *fake.Registry) {
+	req := mcp.CallToolRequest{}
+	req.Params.Name = tc.tool
+	req.Params.Arguments = tc.args
+	res, err := c.CallTool(context.Background(), req)
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	isError := tc.isError || tc.invalid
+	if res.IsError != isError {
+		t.Fatalf("isError = %v, want %v; result: %s", res.IsError, isError, text(res))
+	}
+	if len(res.Content) == 0 {
+		t.Fatal("result has no content")
+	}
+	if tc.invalid && len(tc.requests) > 0 {
+		status("INVALID_ARGUMENT")(t, res)
+	}
+	if !isError && !strings.HasPrefix(tc.tool, "delete_") && !strings.HasSuffix(tc.tool, "_contents") {
+		object(t, res)
+	}
+
+	got := f.Requests()
+	if len(got) != len(tc.requests) {
+		var sent []string
+		for _, r := range got {
+			sent = append(sent, r.Method+" "+r.Path)
+		}
+		t.Fatalf("sent %d requests %q, want %d", len(got), sent, len(tc.requests))
+	}
+	for i, want := range tc.requests {
+		checkRequest(t, got[i], want)
+	}
+	for _, check := range tc.checks {
+		check(t, res)
+	}
+}
+
+func checkRequest(t *testing.T, got fake.Request, want wantRequest) {
+	t.Helper()
+	if got.Method != want.method || got.Path != want.path {
+		t.Errorf("request = %s %s, want %s %s", got.Method, got.Path, want.method, want.path)
+	}
+	if len(got.Query) != 0 || len(want.query) != 0 {
+		if !reflect.DeepEqual(got.Query, want.query) {
+			t.Errorf("%s %s query = %v, want %v", got.Method, got.Path, got.Query, want.query)
+		}
+	}
+	if want.body == "" {
+		if len(got.Body) != 0 {
+			t.Errorf("%s %s body = %s, want none", got.Method, got.Path, got.Body)
+		}
+		return
+	}
+	var gotBody, wantBody any
+	if err := json.Unmarshal(got.Body, &gotBody); err != nil {
+		t.Errorf("%s %s body is not JSON: %v\n%s", got.Method, got.Path, err, got.Body)
+		return
+	}
+	if err := json.Unmarshal([]byte(want.body), &wantBody); err != nil {
+		t.Fatalf("invalid expected body %s: %v", want.body, err)
+	}
+	if !reflect.DeepEqual(gotBody, wantBody) {
+		t.Errorf("%s %s body = %s, want %s", got.Method, got.Path, got.Body, want.body)
+	}
+	if got.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("%s %s Content-Type = %q, want application/json", got.Method, got.Path, got.Header.Get("Content-Type"))
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/models"
+)
+
+// dryRunPlan is the result of a dry run.
+type dryRunPlan struct {
+	DryRun  bool                  `json:"dryRun"`
+	Request client.PlannedRequest `json:"request"`
+	Target  string                `json:"target"`
+	Before  map[string]any        `json:"before"`
+	After   map[string]any        `json:"after"`
+	Changes []fieldChange         `json:"changes"`
+	Notes   []string              `json:"notes,omitempty"`
+}
+
+// fieldChange is one top-level field differing between before and after.
+type fieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// isDryRun reports whether a call with args must not change the registry.
+func isDryRun(cfg *config.APIConfig, args map[string]any) bool {
+	dryRun, _ := args["dryRun"].(bool)
+	return dryRun || cfg.DryRun
+}
+
+// withDryRun adds a dryRun argument to a mutating tool. A dry run validates
+// the call, records the request instead of sending it and returns it along
+// with the target resource before and after the change.
+func withDryRun(cfg *config.APIConfig, rt registryTool, tool models.Tool) models.Tool {
+	if rt.readOnly() {
+		return tool
+	}
+	description := "Validate the call and return the HTTP request it would send, with the resource before and after the change, without sending it."
+	if cfg.DryRun {
+		description += " The server runs in dry-run mode, so nothing is ever sent."
+	}
+	tool.Definition.InputSchema.Properties["dryRun"] = map[string]any{"type": "boolean", "description": description}
+
+	c := client.New(cfg)
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]any)
+		if !ok {
+			return handler(ctx, request)
+		}
+		dryRun := isDryRun(cfg, args)
+		args = maps.Clone(args)
+		delete(args, "dryRun")
+		request.Params.Arguments = args
+		if !dryRun {
+			return handler(ctx, request)
+		}
+
+		ctx, plan := client.WithDryRun(ctx)
+		result, err := handler(ctx, request)
+		if err != nil || len(plan.Requests) == 0 {
+			// Rejected before a request was built, e.g. an invalid ID.
+			return result, err
+		}
+		out, err := planChange(ctx, c, rt, args, plan.Requests[0])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false) // keep "&" in the request URL readable
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal dry run: %v", err)), nil
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	}
+	return tool
+}
+
+// planChange resolves the target of req with a GET and predicts the resource
+// after the change.
+func planChange(ctx context.Context, c *client.Client, rt registryTool, args map[string]any, req client.PlannedRequest) (*dryRunPlan, error) {
+	plan := &dryRunPlan{DryRun: true, Request: req}
+	verb, _, _ := strings.Cut(rt.short, "_")
+
+	target, named := targetName(rt, args)
+	plan.Target = targetString(rt, args)
+
+	// Without an ID there is nothing to look up: the registry assigns one.
+	var before map[string]any
+	var err error
+	if named {
+		before, err = c.Get(ctx, target)
+		var apiErr *client.Error
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			if verb != "create" {
+				plan.Notes = append(plan.Notes, fmt.Sprintf("%s does not exist; the request would probably fail.", plan.Target))
+			}
+		case err != nil:
+			return nil, fmt.Errorf("reading %s: %w", plan.Target, err)
+		case verb == "create":
+			plan.Notes = append(plan.Notes, fmt.Sprintf("%s already exists; the request would fail.", plan.Target))
+		}
+	}
+	plan.Before = before
+
+	var body map[string]any
+	if len(req.Body) > 0 {
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			return nil, fmt.Errorf("decoding request body: %w", err)
+		}
+	}
+
+	switch verb {
+	case "create":
+		plan.After = maps.Clone(body)
+		if plan.After == nil {
+			plan.After = map[string]any{}
+		}
+		if named {
+			plan.After["name"] = plan.Target
+		} else {
+			delete(plan.After, "name")
+			plan.Notes = append(plan.Notes, "No ID was given, so the registry chooses one.")
+		}
+	case "update":
+		plan.After = applyUpdate(before, body, args)
+	case "replace":
+		plan.After = body
+	case "delete":
+		if force, _ := args["force"].(bool); force {
+			plan.Notes = append(plan.Notes, "With force=true all children are deleted too.")
+		}
+	case "tag":
+		plan.After = maps.Clone(before)
+		if plan.After != nil {
+			tags, _ := plan.After["revisionTags"].([]any)
+			plan.After["revisionTags"] = append(slices.Clone(tags), body["tag"])
+		}
+	case "rollback":
+		revision, _ := body["revisionId"].(string)
+		old := target
+		old.Revision = revision
+		if plan.After, err = c.Get(ctx, old); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", old, err)
+		}
+		plan.Notes = append(plan.Notes, "The rollback is recorded as a new revision with the contents of the old one.")
+	}
+	plan.Changes = diff(plan.Before, plan.After)
+	return plan, nil
+}
+
+// applyUpdate predicts the result of an update: the fields in the update
+// mask, or all fields set in the body if there is none, are replaced.
+func applyUpdate(before, body, args map[string]any) map[string]any {
+	if before == nil {
+		if allow, _ := args["allowMissing"].(bool); allow {
+			return body
+		}
+		return nil
+	}
+	after := maps.Clone(before)
+	mask, _ := args["updateMask"].(string)
+	if mask == "" {
+		maps.Copy(after, body)
+		return after
+	}
+	for _, field := range strings.Split(mask, ",") {
+		field, _, _ = strings.Cut(strings.TrimSpace(field), ".")
+		if field == "*" {
+			// Output-only fields are kept by the registry.
+			for k := range after {
+				if _, ok := body[k]; !ok && !strings.HasSuffix(k, "Time") && k != "name" && !strings.HasPrefix(k, "revision") {
+					delete(after, k)
+				}
+			}
+			maps.Copy(after, body)
+			continue
+		}
+		if v, ok := body[field]; ok {
+			after[field] = v
+		} else {
+			delete(after, field)
+		}
+	}
+	return after
+}
+
+// diff lists the top-level fields that differ between before and after.
+func diff(before, after map[string]any) []fieldChange {
+	keys := slices.Sorted(maps.Keys(before))
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	changes := []fieldChange{}
+	for _, k := range keys {
+		if !reflect.DeepEqual(before[k], after[k]) {
+			changes = append(changes, fieldChange{Field: k, Before: before[k], After: after[k]})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/config"
+)
+
+// planOf decodes the result of a dry run.
+func planOf(t *testing.T, text string) dryRunPlan {
+	t.Helper()
+	var plan dryRunPlan
+	if err := json.Unmarshal([]byte(text), &plan); err != nil {
+		t.Fatalf("dry run result %q: %v", text, err)
+	}
+	if !plan.DryRun {
+		t.Errorf("dry run result %q, want dryRun: true", text)
+	}
+	return plan
+}
+
+func TestDryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]any
+		method  string
+		path    string
+		query   url.Values
+		body    string // JSON, "" for none
+		target  string
+		reads   int // GETs sent to plan the change
+		changes []string
+	}{
+		{
+			name:    "create",
+			tool:    "create_api",
+			args:    demo(map[string]any{"apiId": "orders", "displayName": "Orders"}),
+			method:  http.MethodPost,
+			path:    demoLocation + "/apis",
+			query:   url.Values{"apiId": {"orders"}},
+			body:    `{"displayName":"Orders"}`,
+			target:  "projects/demo/locations/global/apis/orders",
+			reads:   1,
+			changes: []string{"displayName", "name"},
+		},
+		{
+			name:    "create without ID",
+			tool:    "create_api",
+			args:    demo(map[string]any{"displayName": "Orders"}),
+			method:  http.MethodPost,
+			path:    demoLocation + "/apis",
+			body:    `{"displayName":"Orders"}`,
+			target:  "projects/demo/locations/global/apis",
+			changes: []string{"displayName"},
+		},
+		{
+			name:    "create version without ID",
+			tool:    "create_version",
+			args:    demo(map[string]any{"api": "petstore", "displayName": "v2"}),
+			method:  http.MethodPost,
+			path:    demoAPI + "/versions",
+			body:    `{"displayName":"v2"}`,
+			target:  "projects/demo/locations/global/apis/petstore/versions",
+			changes: []string{"displayName"},
+		},
+		{
+			name:    "update",
+			tool:    "update_api",
+			args:    demo(map[string]any{"api": "petstore", "description": "Pets.", "updateMask": "description"}),
+			method:  http.MethodPatch,
+			path:    demoAPI,
+			query:   url.Values{"updateMask": {"description"}},
+			body:    `{"description":"Pets."}`,
+			target:  "projects/demo/locations/global/apis/petstore",
+			reads:   1,
+			changes: []string{"description"},
+		},
+		{
+			name:   "delete",
+			tool:   "delete_deployment",
+			args:   demo(map[string]any{"api": "petstore", "deployment": "prod"}),
+			method: http.MethodDelete,
+			path:   demoAPI + "/deployments/prod",
+			target: "projects/demo/locations/global/apis/petstore/deployments/prod",
+			reads:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up := &upstream{}
+			f := up.reset()
+			srv := httptest.NewServer(up)
+			defer srv.Close()
+			cfg := testConfig()
+			cfg.BaseURL = srv.URL
+			cfg.BearerToken = "secret-token"
+			c := serveStdio(t, cfg)
+
+			tt.args["dryRun"] = true
+			res := callTool(t, c, tt.tool, tt.args, nil)
+			if res.IsError {
+				t.Fatalf("dry run failed: %q", text(res))
+			}
+			for _, r := range f.Requests() {
+				if r.Method != http.MethodGet {
+					t.Errorf("dry run sent %s %s upstream", r.Method, r.Path)
+				}
+			}
+			if n := len(f.Requests()); n != tt.reads {
+				t.Errorf("dry run sent %d reads, want %d", n, tt.reads)
+			}
+
+			plan := planOf(t, text(res))
+			if plan.Target != tt.target {
+				t.Errorf("plan target = %s, want %s", plan.Target, tt.target)
+			}
+			if plan.Request.Method != tt.method {
+				t.Errorf("planned method = %s, want %s", plan.Request.Method, tt.method)
+			}
+			u, err := url.Parse(plan.Request.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Host != strings.TrimPrefix(srv.URL, "http://") || u.Path != tt.path {
+				t.Errorf("planned URL = %s, want %s%s", plan.Request.URL, srv.URL, tt.path)
+			}
+			for k, v := range tt.query {
+				if got := u.Query()[k]; len(got) != 1 || got[0] != v[0] {
+					t.Errorf("planned query %s = %v, want %v", k, got, v)
+				}
+			}
+			if got := plan.Request.Header["Authorization"]; got != client.Redacted {
+				t.Errorf("planned Authorization = %q, want %s", got, client.Redacted)
+			}
+			if strings.Contains(text(res), "secret-token") {
+				t.Error("the plan contains the bearer token")
+			}
+			if tt.body == "" {
+				if len(plan.Request.Body) != 0 {
+					t.Errorf("planned body = %s, want none", plan.Request.Body)
+				}
+			} else {
+				var body bytes.Buffer
+				if err := json.Compact(&body, plan.Request.Body); err != nil || body.String() != tt.body {
+					t.Errorf("planned body = %s, want %s", plan.Request.Body, tt.body)
+				}
+			}
+
+			if tt.changes == nil {
+				// A delete leaves nothing behind.
+				if plan.Before == nil || plan.After != nil {
+					t.Errorf("plan before = %v and after = %v, want the deployment and nothing", plan.Before, plan.After)
+				}
+				return
+			}
+			if name, ok := plan.After["name"]; ok && name != tt.target {
+				t.Errorf("planned name = %v, want %s", name, tt.target)
+			}
+			var changed []string
+			for _, c := range plan.Changes {
+				changed = append(changed, c.Field)
+			}
+			if strings.Join(changed, ",") != strings.Join(tt.changes, ",") {
+				t.Errorf("changed fields = %v, want %v", changed, tt.changes)
+			}
+		})
+	}
+}
+
+func TestDryRunRedactsAPIKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		inHeader bool
+	}{
+		{"header", config.APIKeyInHeader, true},
+		{"query", config.APIKeyInQuery, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up := &upstream{}
+			f := up.reset()
+			srv := httptest.NewServer(up)
+			defer srv.Close()
+			cfg := testConfig()
+			cfg.BaseURL = srv.URL
+			cfg.APIKey = "secret-key"
+			cfg.APIKeyName = "key"
+			cfg.APIKeyIn = tt.in
+			cfg.DryRun = true
+			c := serveStdio(t, cfg)
+
+			res := callTool(t, c, "delete_api", demo(map[string]any{"api": "petstore", "force": true}), nil)
+			if res.IsError {
+				t.Fatalf("dry run failed: %q", text(res))
+			}
+			if deletes(f) != 0 {
+				t.Error("DRY_RUN sent a delete")
+			}
+			if strings.Contains(text(res), "secret-key") {
+				t.Errorf("the plan contains the API key: %s", text(res))
+			}
+
+			plan := planOf(t, text(res))
+			u, err := url.Parse(plan.Request.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			header, query := plan.Request.Header["Key"], u.Query().Get("key")
+			if tt.inHeader && (header != client.Redacted || query != "") {
+				t.Errorf("planned key header %q and query %q, want only the header redacted", header, query)
+			}
+			if !tt.inHeader && (query != client.Redacted || header != "") {
+				t.Errorf("planned key header %q and query %q, want only the query redacted", header, query)
+			}
+			if u.Query().Get("force") != "true" {
+				t.Errorf("planned URL %s lost the force parameter", plan.Request.URL)
+			}
+		})
+	}
+}
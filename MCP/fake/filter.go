@@ -0,0 +1,371 @@
+package fake
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// filter is a parsed list filter. The registry accepts CEL expressions over
+// the fields of the listed resources; the fake supports the subset used in
+// practice:
+//
+//	labels.team == "payments" && !(availability == 'DEPRECATED')
+//	displayName.contains("Pet") || name.startsWith("projects/p/locations/global/apis/a")
+//	has(labels.tier) && createTime > "2024-01-01T00:00:00Z"
+//
+// Comparisons (==, !=, <, <=, >, >=) take string, number and boolean
+// literals; missing fields compare as their zero value.
+type filter struct {
+	eval func(map[string]any) any
+}
+
+func (f filter) match(item map[string]any) bool {
+	b, _ := f.eval(item).(bool)
+	return b
+}
+
+func parseFilter(expr string) (filter, error) {
+	toks, err := tokenize(expr)
+	if err != nil {
+		return filter{}, err
+	}
+	p := &parser{toks: toks}
+	eval, err := p.or()
+	if err != nil {
+		return filter{}, err
+	}
+	if p.peek() != "" {
+		return filter{}, fmt.Errorf("unexpected %q", p.peek())
+	}
+	return filter{eval: eval}, nil
+}
+
+type token struct {
+	text    string
+	literal any // Set for string, number and boolean literals
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(s) && rune(s[j]) != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j == len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			toks = append(toks, token{text: s[i : j+1], literal: b.String()})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1]))):
+			j := i + 1
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			n, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", s[i:j])
+			}
+			toks = append(toks, token{text: s[i:j], literal: n})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || s[j] == '_') {
+				j++
+			}
+			t := token{text: s[i:j]}
+			switch t.text {
+			case "true":
+				t.literal = true
+			case "false":
+				t.literal = false
+			}
+			toks = append(toks, t)
+			i = j
+		default:
+			op := s[i : i+1]
+			if i+1 < len(s) {
+				switch two := s[i : i+2]; two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					op = two
+				}
+			}
+			if !strings.Contains("==!=<=>=&&||<>!().,", op) {
+				return nil, fmt.Errorf("unexpected %q", op)
+			}
+			toks = append(toks, token{text: op})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos].text
+	}
+	return ""
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *parser) expect(text string) error {
+	if p.peek() != text {
+		return fmt.Errorf("expected %q", text)
+	}
+	p.pos++
+	return nil
+}
+
+type evalFunc = func(map[string]any) any
+
+func (p *parser) or() (evalFunc, error) {
+	left, err := p.and()
+	for err == nil && p.peek() == "||" {
+		p.pos++
+		var right evalFunc
+		if right, err = p.and(); err == nil {
+			l := left
+			left = func(item map[string]any) any { return truthy(l(item)) || truthy(right(item)) }
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (evalFunc, error) {
+	left, err := p.unary()
+	for err == nil && p.peek() == "&&" {
+		p.pos++
+		var right evalFunc
+		if right, err = p.unary(); err == nil {
+			l := left
+			left = func(item map[string]any) any { return truthy(l(item)) && truthy(right(item)) }
+		}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (evalFunc, error) {
+	if p.peek() == "!" {
+		p.pos++
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(item map[string]any) any { return !truthy(operand(item)) }, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (evalFunc, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return left, nil
+	}
+	p.pos++
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(item map[string]any) any {
+		return compare(op, left(item), right(item))
+	}, nil
+}
+
+// operand parses a literal, a parenthesized expression, has(field) or a
+// field path, optionally followed by a string method call.
+func (p *parser) operand() (evalFunc, error) {
+	switch t := p.peek(); {
+	case t == "":
+		return nil, fmt.Errorf("unexpected end of filter")
+	case t == "(":
+		p.pos++
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case p.toks[p.pos].literal != nil:
+		v := p.next().literal
+		return func(map[string]any) any { return v }, nil
+	case t == "has":
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		path, err := p.path()
+		if err != nil {
+			return nil, err
+		}
+		return func(item map[string]any) any { return lookupPath(item, path) != nil }, p.expect(")")
+	}
+
+	path, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+	field := func(item map[string]any) any { return lookupPath(item, path) }
+	if p.peek() != "(" {
+		return field, nil
+	}
+	// The last path element is a method of the field before it.
+	method := path[len(path)-1]
+	path = path[:len(path)-1]
+	if len(path) == 0 {
+		return nil, fmt.Errorf("unknown function %q", method)
+	}
+	p.pos++
+	arg, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	var fn func(s, arg string) bool
+	switch method {
+	case "contains":
+		fn = strings.Contains
+	case "startsWith":
+		fn = strings.HasPrefix
+	case "endsWith":
+		fn = strings.HasSuffix
+	case "matches":
+		fn = func(s, pattern string) bool {
+			ok, _ := regexp.MatchString(pattern, s)
+			return ok
+		}
+	default:
+		return nil, fmt.Errorf("unknown function %q", method)
+	}
+	return func(item map[string]any) any {
+		s, _ := lookupPath(item, path).(string)
+		a, _ := arg(item).(string)
+		return fn(s, a)
+	}, nil
+}
+
+func (p *parser) path() ([]string, error) {
+	var path []string
+	for {
+		t := p.peek()
+		if t == "" || p.toks[p.pos].literal != nil || !(unicode.IsLetter(rune(t[0])) || t[0] == '_') {
+			return nil, fmt.Errorf("expected a field name, got %q", t)
+		}
+		path = append(path, p.next().text)
+		if p.peek() != "." {
+			return path, nil
+		}
+		p.pos++
+	}
+}
+
+func lookupPath(item map[string]any, path []string) any {
+	var v any = item
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func truthy(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// compare applies a comparison operator. Numbers compare numerically,
+// anything else by its string form, with nil as the zero value of the other
+// operand's type.
+func compare(op string, a, b any) bool {
+	if n, ok := number(b); ok {
+		if m, ok := number(a); ok || a == nil {
+			return ordered(op, m, n)
+		}
+	}
+	if a == nil {
+		a = zero(b)
+	}
+	if b == nil {
+		b = zero(a)
+	}
+	if x, ok := a.(bool); ok {
+		y, ok := b.(bool)
+		switch op {
+		case "==":
+			return ok && x == y
+		case "!=":
+			return !ok || x != y
+		}
+		return false
+	}
+	return ordered(op, fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func zero(v any) any {
+	switch v.(type) {
+	case bool:
+		return false
+	case float64, int:
+		return 0.0
+	}
+	return ""
+}
+
+func ordered[T int | float64 | string](op string, a, b T) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package fake
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	items := []map[string]any{
+		{
+			"name":         "projects/p/locations/global/apis/petstore",
+			"displayName":  "Swagger Petstore",
+			"availability": "GENERAL",
+			"labels":       map[string]any{"team": "pets", "tier": "public"},
+			"createTime":   "2024-03-01T00:00:00Z",
+			"sizeBytes":    2048.0,
+			"recommended":  true,
+		},
+		{
+			"name":         "projects/p/locations/global/apis/payments",
+			"displayName":  "Payments",
+			"availability": "PREVIEW",
+			"labels":       map[string]any{"team": "payments"},
+			"createTime":   "2023-06-01T00:00:00Z",
+			"sizeBytes":    512.0,
+		},
+	}
+	tests := []struct {
+		expr string
+		want []string // IDs of the matching items
+	}{
+		// Comparisons
+		{`availability == "GENERAL"`, []string{"petstore"}},
+		{`availability != 'GENERAL'`, []string{"payments"}},
+		{`labels.team == "payments"`, []string{"payments"}},
+		{`createTime > "2024-01-01T00:00:00Z"`, []string{"petstore"}},
+		{`createTime <= "2024-01-01T00:00:00Z"`, []string{"payments"}},
+		{`sizeBytes >= 1024`, []string{"petstore"}},
+		{`sizeBytes < 1024.5`, []string{"payments"}},
+		{`recommended == true`, []string{"petstore"}},
+		{`recommended == false`, []string{"payments"}},
+		{`labels.tier == ""`, []string{"payments"}},
+		{`has(labels.tier)`, []string{"petstore"}},
+
+		// Functions
+		{`displayName.contains("Pet")`, []string{"petstore"}},
+		{`name.startsWith("projects/p/locations/global/apis/pay")`, []string{"payments"}},
+		{`name.endsWith("store")`, []string{"petstore"}},
+		{`displayName.matches("^P.*s$")`, []string{"payments"}},
+
+		// Boolean operators and precedence
+		{`labels.team == "pets" && availability == "GENERAL"`, []string{"petstore"}},
+		{`labels.team == "pets" && availability == "PREVIEW"`, nil},
+		{`availability == "PREVIEW" || displayName.contains("Pet")`, []string{"petstore", "payments"}},
+		{`!(availability == "GENERAL")`, []string{"payments"}},
+		{`!has(labels.tier) && sizeBytes < 1024`, []string{"payments"}},
+		{`availability == "GENERAL" || availability == "PREVIEW" && sizeBytes > 4096`, []string{"petstore"}},
+		{`(availability == "GENERAL" || availability == "PREVIEW") && sizeBytes > 4096`, nil},
+		{`!!recommended`, []string{"petstore"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := parseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("parseFilter: %v", err)
+			}
+			var got []string
+			for _, item := range items {
+				if f.match(item) {
+					got = append(got, item["name"].(string)[len("projects/p/locations/global/apis/"):])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterInvalid(t *testing.T) {
+	for _, expr := range []string{
+		`availability ==`,
+		`availability == "GENERAL`,
+		`(availability == "GENERAL"`,
+		`availability == "GENERAL")`,
+		`availability = "GENERAL"`,
+		`labels.team == "pets" &&`,
+		`displayName.reverse()`,
+		`contains("Pet")`,
+		`has("tier")`,
+		`availability ~ "GENERAL"`,
+		`1.2.3 > sizeBytes`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := parseFilter(expr); err == nil {
+				t.Errorf("parseFilter(%q) succeeded", expr)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+// Package fake is an in-memory implementation of the Registry REST API (/v1)
+// for tests and offline demos. It covers APIs, versions, specs and deployments
+// with their revisions, tags and rollbacks, and artifacts, including forced
+// deletes, CEL filters, pagination and getContents.
+//
+// A Registry is an http.Handler, so it can be embedded with httptest:
+//
+//	srv := httptest.NewServer(fake.New())
+//	defer srv.Close()
+//	cfg := &config.APIConfig{BaseURL: srv.URL}
+//
+// Projects and locations are implicit: every project and location exists.
+// Errors are reported as google.rpc.Status in the {"error": {...}} envelope
+// used by Google REST endpoints.
+package fake
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/registry-api/mcp-server/names"
+)
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// WithToken makes the registry reject requests that do not carry
+// "Authorization: Bearer <token>".
+func WithToken(token string) Option {
+	return func(r *Registry) {
+		r.token = token
+	}
+}
+
+// WithClock sets the source of the timestamps the registry records.
+func WithClock(now func() time.Time) Option {
+	return func(r *Registry) {
+		r.now = now
+	}
+}
+
+// Request is a request received by the registry, as recorded for tests.
+type Request struct {
+	Method string
+	Path   string // Unescaped, including any custom verb such as ":rollback"
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Registry is the fake registry. It is safe for concurrent use.
+type Registry struct {
+	token string
+	now   func() time.Time
+
+	mu        sync.Mutex
+	resources map[string]*resource // keyed by name without revision
+	requests  []Request
+	serial    int // Source of revision IDs and generated resource IDs
+}
+
+// resource is a stored API, version, spec, deployment or artifact.
+type resource struct {
+	name       names.Name
+	createTime time.Time
+	// revisions holds the revisions of a spec or deployment, newest first.
+	// Other resources have exactly one, which is updated in place.
+	revisions []*revision
+}
+
+type revision struct {
+	id         string
+	createTime time.Time
+	updateTime time.Time
+	fields     map[string]any // Settable fields other than contents
+	contents   []byte
+	tags       []string
+}
+
+func (res *resource) current() *revision {
+	return res.revisions[0]
+}
+
+// New returns an empty registry.
+func New(opts ...Option) *Registry {
+	r := &Registry{
+		now:       time.Now,
+		resources: map[string]*resource{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Requests returns the requests received so far, oldest first.
+func (r *Registry) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.requests)
+}
+
+// ServeHTTP implements the /v1 REST surface.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, errorf(invalidArgument, "reading request body: %v", err))
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+
+	if r.token != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+		writeError(w, errorf(unauthenticated, "request had invalid authentication credentials"))
+		return
+	}
+	out, err := r.route(req.Method, req.URL.Path, req.URL.Query(), body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if c, ok := out.(contents); ok {
+		w.Header().Set("Content-Type", c.mimeType)
+		w.Write(c.data)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// contents is the raw response of a getContents call.
+type contents struct {
+	mimeType string
+	data     []byte
+}
+
+// route dispatches a request by the shape of its path: a collection is
+// listed or created in, a resource is read, changed or deleted, and a custom
+// verb selects the remaining methods.
+func (r *Registry) route(method, path string, query url.Values, body []byte) (any, error) {
+	rest, ok := strings.CutPrefix(path, "/v1/")
+	if !ok {
+		return nil, errorf(notFound, "no such method: %s %s", method, path)
+	}
+	verb := ""
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		rest, verb = rest[:i], rest[i+1:]
+	}
+
+	// A trailing collection is parsed as the name of its parent.
+	parts := strings.Split(rest, "/")
+	collection := ""
+	if len(parts)%2 == 1 {
+		collection = parts[len(parts)-1]
+		rest = strings.Join(parts[:len(parts)-1], "/")
+	}
+	name, err := names.Parse(rest)
+	if err != nil {
+		return nil, errorf(invalidArgument, "%v", err)
+	}
+	if len(name.Segments) < 2 || name.Segments[1].Collection != names.Locations {
+		return nil, errorf(notFound, "no such method: %s %s", method, path)
+	}
+
+	switch {
+	case collection != "" && verb == "":
+		if !slices.Contains(names.Children(name.Collection()), collection) {
+			return nil, errorf(notFound, "no such collection: %s", collection)
+		}
+		switch method {
+		case http.MethodGet:
+			return r.list(name, collection, query)
+		case http.MethodPost:
+			return r.create(name, collection, query, body)
+		}
+	case len(name.Segments) == 2:
+		// Locations themselves are not part of the surface.
+	case verb == "":
+		switch {
+		case method == http.MethodGet:
+			return r.get(name)
+		case method == http.MethodPatch && name.Collection() != names.Artifacts:
+			return r.update(name, query, body)
+		case method == http.MethodPut && name.Collection() == names.Artifacts:
+			return r.replace(name, body)
+		case method == http.MethodDelete:
+			return r.delete(name, query)
+		}
+	case verb == "getContents" && method == http.MethodGet:
+		if c := name.Collection(); c == names.Specs || c == names.Artifacts {
+			return r.contents(name)
+		}
+	case revisioned(name.Collection()):
+		switch {
+		case verb == "listRevisions" && method == http.MethodGet:
+			return r.listRevisions(name, query)
+		case verb == "tagRevision" && method == http.MethodPost:
+			return r.tagRevision(name, body)
+		case verb == "rollback" && method == http.MethodPost:
+			return r.rollback(name, body)
+		case verb == "deleteRevision" && method == http.MethodDelete:
+			return r.deleteRevision(name)
+		}
+	}
+	return nil, errorf(notFound, "no such method: %s %s", method, path)
+}
+
+// revisioned reports whether resources in collection keep revisions.
+func revisioned(collection string) bool {
+	return collection == names.Specs || collection == names.Deployments
+}
+
+// lookup returns the resource name refers to and the revision selected by its
+// revision ID or tag, or the current revision.
+func (r *Registry) lookup(name names.Name) (*resource, *revision, error) {
+	key := name
+	key.Revision = ""
+	res, ok := r.resources[key.String()]
+	if !ok {
+		return nil, nil, errorf(notFound, "%s not found", key)
+	}
+	if name.Revision == "" {
+		return res, res.current(), nil
+	}
+	for _, rev := range res.revisions {
+		if rev.id == name.Revision || slices.Contains(rev.tags, name.Revision) {
+			return res, rev, nil
+		}
+	}
+	return nil, nil, errorf(notFound, "%s not found", name)
+}
+
+// exists reports whether the parent of a new or listed resource exists.
+// Projects and locations always do.
+func (r *Registry) exists(parent names.Name) bool {
+	if len(parent.Segments) <= 2 {
+		return true
+	}
+	_, ok := r.resources[parent.String()]
+	return ok
+}
+
+// children returns the names of the resources below name.
+func (r *Registry) children(name names.Name) []string {
+	prefix := name.String() + "/"
+	var out []string
+	for key := range r.resources {
+		if strings.HasPrefix(key, prefix) {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// nextID returns a new 8-digit hexadecimal ID. IDs are sequential so that
+// runs against a fresh registry are reproducible.
+func (r *Registry) nextID() string {
+	r.serial++
+	return hexID(r.serial)
+}
+
+func hexID(n int) string {
+	const digits = "0123456789abcdef"
+	b := []byte("00000000")
+	for i := len(b) - 1; i >= 0 && n > 0; i-- {
+		b[i] = digits[n%16]
+		n /= 16
+	}
+	return string(b)
+}
+
+// render returns the JSON form of a revision of res. Revision names carry the
+// revision ID when withRevision is set, as in the revision methods.
+func render(res *resource, rev *revision, withRevision bool) map[string]any {
+	out := make(map[string]any, len(rev.fields)+8)
+	for k, v := range rev.fields {
+		out[k] = v
+	}
+	name := res.name
+	out["createTime"] = timestamp(res.createTime)
+	if revisioned(name.Collection()) {
+		if withRevision {
+			name.Revision = rev.id
+		}
+		out["revisionId"] = rev.id
+		out["revisionCreateTime"] = timestamp(rev.createTime)
+		out["revisionUpdateTime"] = timestamp(rev.updateTime)
+		if len(rev.tags) > 0 {
+			out["revisionTags"] = slices.Clone(rev.tags)
+		}
+	} else {
+		out["updateTime"] = timestamp(rev.updateTime)
+	}
+	out["name"] = name.String()
+	if rev.contents != nil {
+		data := uncompressed(rev.contents)
+		sum := sha256.Sum256(data)
+		out["hash"] = hex.EncodeToString(sum[:])
+		out["sizeBytes"] = len(data)
+	}
+	return out
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// uncompressed returns gzipped data decompressed, and anything else as is.
+// Hashes and sizes describe the uncompressed contents.
+func uncompressed(data []byte) []byte {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return data
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// decodeContents decodes the base64 contents field of a request body, as
+// bytes fields are encoded in JSON.
+func decodeContents(s string) ([]byte, error) {
+	if data, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return data, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+// Codes of error responses.
+const (
+	invalidArgument    = "INVALID_ARGUMENT"
+	notFound           = "NOT_FOUND"
+	alreadyExists      = "ALREADY_EXISTS"
+	failedPrecondition = "FAILED_PRECONDITION"
+	unauthenticated    = "UNAUTHENTICATED"
+)
+
+// httpStatus maps error codes to the HTTP status they are sent with.
+var httpStatus = map[string]int{
+	invalidArgument:    http.StatusBadRequest,
+	notFound:           http.StatusNotFound,
+	alreadyExists:      http.StatusConflict,
+	failedPrecondition: http.StatusBadRequest,
+	unauthenticated:    http.StatusUnauthorized,
+}
+
+// statusError is an error response.
+type statusError struct {
+	code    string
+	message string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+func errorf(code, format string, args ...any) error {
+	return &statusError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*statusError)
+	if !ok {
+		e = &statusError{code: invalidArgument, message: err.Error()}
+	}
+	status := httpStatus[e.code]
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"code": status, "message": e.message, "status": e.code},
+	})
+}
@@ -0,0 +1,158 @@
+package fake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const location = "/v1/projects/p/locations/global"
+
+// call sends a request to r and returns the status and decoded response.
+func call(t *testing.T, r *Registry, method, path string, query url.Values) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path+"?"+query.Encode(), strings.NewReader("{}"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var out map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("%s %s: %v\n%s", method, path, err, w.Body)
+	}
+	return w.Code, out
+}
+
+// errorStatus returns the status of an error response, or "".
+func errorStatus(out map[string]any) string {
+	e, _ := out["error"].(map[string]any)
+	s, _ := e["status"].(string)
+	return s
+}
+
+func TestPagination(t *testing.T) {
+	r := New()
+	for _, id := range []string{"a3", "a1", "a5", "a2", "a4"} {
+		if status, out := call(t, r, http.MethodPost, location+"/apis", url.Values{"apiId": {id}}); status != http.StatusOK {
+			t.Fatalf("create %s = %d: %v", id, status, out)
+		}
+	}
+
+	var ids []string
+	pages := 0
+	query := url.Values{"pageSize": {"2"}}
+	for {
+		status, out := call(t, r, http.MethodGet, location+"/apis", query)
+		if status != http.StatusOK {
+			t.Fatalf("list = %d: %v", status, out)
+		}
+		pages++
+		apis, _ := out["apis"].([]any)
+		if len(apis) > 2 {
+			t.Errorf("page %d has %d items, want at most 2", pages, len(apis))
+		}
+		for _, api := range apis {
+			name := api.(map[string]any)["name"].(string)
+			ids = append(ids, name[strings.LastIndex(name, "/")+1:])
+		}
+		token, _ := out["nextPageToken"].(string)
+		if token == "" {
+			break
+		}
+		query.Set("pageToken", token)
+	}
+	if got := strings.Join(ids, ","); got != "a1,a2,a3,a4,a5" || pages != 3 {
+		t.Errorf("listed %s in %d pages, want a1,a2,a3,a4,a5 in 3", got, pages)
+	}
+
+	// Filters apply before paging.
+	_, out := call(t, r, http.MethodGet, location+"/apis", url.Values{"pageSize": {"1"}, "filter": {`name.endsWith("4") || name.endsWith("5")`}})
+	if apis, _ := out["apis"].([]any); len(apis) != 1 || out["nextPageToken"] == nil {
+		t.Errorf("filtered first page = %v, want one item and a next page", out)
+	}
+}
+
+func TestPaginationInvalid(t *testing.T) {
+	r := New()
+	r.Seed("p", "global")
+	token := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for _, query := range []url.Values{
+		{"pageSize": {"-1"}},
+		{"pageSize": {"ten"}},
+		{"pageToken": {"not a token"}},
+		{"pageToken": {token("-1")}},
+		{"pageToken": {token("3")}}, // past the two APIs
+		{"filter": {`availability ==`}},
+		{"filter": {`displayName.reverse()`}},
+	} {
+		t.Run(query.Encode(), func(t *testing.T) {
+			status, out := call(t, r, http.MethodGet, location+"/apis", query)
+			if status != http.StatusBadRequest || errorStatus(out) != invalidArgument {
+				t.Errorf("list = %d %v, want 400 INVALID_ARGUMENT", status, out)
+			}
+		})
+	}
+}
+
+func TestDeleteChildren(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		force   bool
+		status  string   // Error status, "" for success
+		deleted []string // Paths that must be gone afterwards
+		kept    []string // Paths that must remain
+	}{
+		{
+			name:   "children without force",
+			path:   location + "/apis/payments",
+			status: failedPrecondition,
+			kept:   []string{location + "/apis/payments", location + "/apis/payments/versions/v1alpha1"},
+		},
+		{
+			name:    "children with force",
+			path:    location + "/apis/petstore",
+			force:   true,
+			deleted: []string{location + "/apis/petstore", location + "/apis/petstore/versions/v1", location + "/apis/petstore/versions/v1/specs/openapi", location + "/apis/petstore/deployments/prod"},
+			kept:    []string{location + "/apis/payments", location + "/apis/payments/versions/v1alpha1"},
+		},
+		{
+			name:    "no children",
+			path:    location + "/apis/payments/versions/v1alpha1",
+			deleted: []string{location + "/apis/payments/versions/v1alpha1"},
+			kept:    []string{location + "/apis/payments"},
+		},
+		{
+			name:    "no children with force",
+			path:    location + "/artifacts/style-guide",
+			force:   true,
+			deleted: []string{location + "/artifacts/style-guide"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Seed("p", "global")
+			query := url.Values{}
+			if tt.force {
+				query.Set("force", "true")
+			}
+			status, out := call(t, r, http.MethodDelete, tt.path, query)
+			if got := errorStatus(out); got != tt.status || (tt.status == "" && status != http.StatusOK) {
+				t.Errorf("delete = %d %v, want status %q", status, out, tt.status)
+			}
+			for _, path := range tt.deleted {
+				if status, _ := call(t, r, http.MethodGet, path, nil); status != http.StatusNotFound {
+					t.Errorf("GET %s after delete = %d, want 404", path, status)
+				}
+			}
+			for _, path := range tt.kept {
+				if status, _ := call(t, r, http.MethodGet, path, nil); status != http.StatusOK {
+					t.Errorf("GET %s after delete = %d, want 200", path, status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,555 @@
+package fake
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/registry-api/mcp-server/names"
+)
+
+// settable lists the fields callers may set on each collection, besides
+// contents. Output-only fields in a request body are ignored, like the
+// registry does.
+var settable = map[string][]string{
+	names.Apis:        {"displayName", "description", "availability", "recommendedVersion", "recommendedDeployment", "labels", "annotations"},
+	names.Versions:    {"displayName", "description", "state", "primarySpec", "labels", "annotations"},
+	names.Specs:       {"filename", "description", "mimeType", "sourceUri", "labels", "annotations"},
+	names.Deployments: {"displayName", "description", "apiSpecRevision", "endpointUri", "externalChannelUri", "intendedAudience", "accessGuidance", "labels", "annotations"},
+	names.Artifacts:   {"mimeType", "labels", "annotations"},
+}
+
+var outputOnly = []string{"name", "createTime", "updateTime", "revisionId", "revisionCreateTime", "revisionUpdateTime", "revisionTags", "hash", "sizeBytes"}
+
+// revisionFields are the fields whose change commits a new revision.
+var revisionFields = map[string][]string{
+	names.Specs:       {"contents"},
+	names.Deployments: {"apiSpecRevision", "endpointUri"},
+}
+
+// listKeys are the response fields holding the items of each collection.
+var listKeys = map[string]string{
+	names.Apis:        "apis",
+	names.Versions:    "apiVersions",
+	names.Specs:       "apiSpecs",
+	names.Deployments: "apiDeployments",
+	names.Artifacts:   "artifacts",
+}
+
+// idParams are the query parameters naming the ID of a new resource.
+var idParams = map[string]string{
+	names.Apis:        "apiId",
+	names.Versions:    "apiVersionId",
+	names.Specs:       "apiSpecId",
+	names.Deployments: "apiDeploymentId",
+	names.Artifacts:   "artifactId",
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// tagPattern is the format of revision tags.
+var tagPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{3,39}$`)
+
+// message is a decoded request body.
+type message struct {
+	fields   map[string]any
+	contents []byte // nil unless the body sets contents
+}
+
+// decode parses the request body for a resource in collection. Unknown fields
+// and values of the wrong type are rejected.
+func decode(collection string, body []byte) (message, error) {
+	m := message{fields: map[string]any{}}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return m, nil
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return m, errorf(invalidArgument, "invalid JSON payload: %v", err)
+	}
+	for k, v := range raw {
+		switch {
+		case slices.Contains(outputOnly, k):
+		case k == "contents" && (collection == names.Specs || collection == names.Artifacts):
+			s, ok := v.(string)
+			if !ok {
+				return m, errorf(invalidArgument, "invalid value for contents: must be a base64 string")
+			}
+			data, err := decodeContents(s)
+			if err != nil {
+				return m, errorf(invalidArgument, "invalid value for contents: must be base64-encoded bytes")
+			}
+			m.contents = data
+		case !slices.Contains(settable[collection], k):
+			return m, errorf(invalidArgument, "unknown field %q in %s", k, strings.TrimSuffix(collection, "s"))
+		case k == "labels" || k == "annotations":
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return m, errorf(invalidArgument, "invalid value for %s: must be an object", k)
+			}
+			for key, value := range obj {
+				if _, ok := value.(string); !ok {
+					return m, errorf(invalidArgument, "invalid value for %s.%s: must be a string", k, key)
+				}
+			}
+			if len(obj) > 0 {
+				m.fields[k] = obj
+			}
+		default:
+			s, ok := v.(string)
+			if !ok {
+				return m, errorf(invalidArgument, "invalid value for %s: must be a string", k)
+			}
+			if s != "" {
+				m.fields[k] = s
+			}
+		}
+	}
+	return m, nil
+}
+
+// get implements the Get methods.
+func (r *Registry) get(name names.Name) (any, error) {
+	res, rev, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return render(res, rev, name.Revision != ""), nil
+}
+
+// contents implements GetApiSpecContents and GetArtifactContents. Contents
+// are returned as stored, with their media type as Content-Type.
+func (r *Registry) contents(name names.Name) (any, error) {
+	_, rev, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	mimeType, _ := rev.fields["mimeType"].(string)
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return contents{mimeType: mimeType, data: rev.contents}, nil
+}
+
+// list implements the List methods. The parent may use "-" as a wildcard for
+// any of its IDs.
+func (r *Registry) list(parent names.Name, collection string, query url.Values) (any, error) {
+	wildcard := slices.ContainsFunc(parent.Segments, func(s names.Segment) bool { return s.ID == names.Wildcard })
+	if !wildcard && !r.exists(parent) {
+		return nil, errorf(notFound, "%s not found", parent)
+	}
+	var matches []*resource
+	for _, res := range r.resources {
+		if res.name.Collection() == collection && matchParent(parent, res.name.Parent()) {
+			matches = append(matches, res)
+		}
+	}
+	slices.SortFunc(matches, func(a, b *resource) int { return strings.Compare(a.name.String(), b.name.String()) })
+
+	items := make([]map[string]any, 0, len(matches))
+	for _, res := range matches {
+		items = append(items, render(res, res.current(), false))
+	}
+	return page(listKeys[collection], items, query)
+}
+
+func matchParent(pattern, parent names.Name) bool {
+	if len(pattern.Segments) != len(parent.Segments) {
+		return false
+	}
+	for i, s := range pattern.Segments {
+		if s.Collection != parent.Segments[i].Collection || (s.ID != names.Wildcard && s.ID != parent.Segments[i].ID) {
+			return false
+		}
+	}
+	return true
+}
+
+// listRevisions implements ListApiSpecRevisions and
+// ListApiDeploymentRevisions, newest first.
+func (r *Registry) listRevisions(name names.Name, query url.Values) (any, error) {
+	res, _, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]map[string]any, 0, len(res.revisions))
+	for _, rev := range res.revisions {
+		items = append(items, render(res, rev, true))
+	}
+	return page(listKeys[res.name.Collection()], items, query)
+}
+
+// page filters items and returns the page selected by query, with a token for
+// the next one. Page tokens encode the offset of the page.
+func page(key string, items []map[string]any, query url.Values) (any, error) {
+	if expr := query.Get("filter"); expr != "" {
+		f, err := parseFilter(expr)
+		if err != nil {
+			return nil, errorf(invalidArgument, "invalid filter %q: %v", expr, err)
+		}
+		items = slices.DeleteFunc(items, func(item map[string]any) bool { return !f.match(item) })
+	}
+
+	size := defaultPageSize
+	if s := query.Get("pageSize"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, errorf(invalidArgument, "invalid page size %q", s)
+		}
+		if n > 0 {
+			size = min(n, maxPageSize)
+		}
+	}
+	offset := 0
+	if token := query.Get("pageToken"); token != "" {
+		data, err := base64.RawURLEncoding.DecodeString(token)
+		if err == nil {
+			offset, err = strconv.Atoi(string(data))
+		}
+		if err != nil || offset < 0 || offset > len(items) {
+			return nil, errorf(invalidArgument, "invalid page token %q", token)
+		}
+	}
+
+	end := min(offset+size, len(items))
+	out := map[string]any{key: items[offset:end]}
+	if end < len(items) {
+		out["nextPageToken"] = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	return out, nil
+}
+
+// create implements the Create methods. Without an ID in the query the
+// registry chooses one.
+func (r *Registry) create(parent names.Name, collection string, query url.Values, body []byte) (any, error) {
+	id := query.Get(idParams[collection])
+	if id == "" {
+		id = strings.TrimSuffix(collection, "s") + "-" + r.nextID()
+	} else if err := names.ValidateID(collection, id); err != nil || strings.Contains(id, "@") || id == names.Wildcard {
+		return nil, errorf(invalidArgument, "invalid %s: %q", idParams[collection], id)
+	}
+	if !r.exists(parent) {
+		return nil, errorf(notFound, "%s not found", parent)
+	}
+	msg, err := decode(collection, body)
+	if err != nil {
+		return nil, err
+	}
+	name := names.Name{Segments: append(slices.Clone(parent.Segments), names.Segment{Collection: collection, ID: id})}
+	if _, ok := r.resources[name.String()]; ok {
+		return nil, errorf(alreadyExists, "%s already exists", name)
+	}
+	res := r.insert(name, msg)
+	return render(res, res.current(), false), nil
+}
+
+// insert stores a new resource.
+func (r *Registry) insert(name names.Name, msg message) *resource {
+	now := r.now()
+	rev := &revision{createTime: now, updateTime: now, fields: msg.fields, contents: msg.contents}
+	if revisioned(name.Collection()) {
+		rev.id = r.nextID()
+	}
+	res := &resource{name: name, createTime: now, revisions: []*revision{rev}}
+	r.resources[name.String()] = res
+	return res
+}
+
+// update implements the Update methods. Without an update mask every field
+// set in the body is updated; "*" replaces all fields. A spec or deployment
+// gets a new revision when a revision field changes.
+func (r *Registry) update(name names.Name, query url.Values, body []byte) (any, error) {
+	if name.Revision != "" {
+		return nil, errorf(invalidArgument, "%s: revisions cannot be updated", name)
+	}
+	collection := name.Collection()
+	msg, err := decode(collection, body)
+	if err != nil {
+		return nil, err
+	}
+	res, _, err := r.lookup(name)
+	if err != nil {
+		if allow, _ := strconv.ParseBool(query.Get("allowMissing")); allow && r.exists(name.Parent()) {
+			res := r.insert(name, msg)
+			return render(res, res.current(), false), nil
+		}
+		return nil, err
+	}
+
+	var mask []string
+	for _, field := range strings.Split(query.Get("updateMask"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			mask = append(mask, camelCase(field))
+		}
+	}
+	cur := res.current()
+	fields := maps.Clone(cur.fields)
+	data := cur.contents
+	switch {
+	case len(mask) == 0:
+		maps.Copy(fields, msg.fields)
+		if msg.contents != nil {
+			data = msg.contents
+		}
+	case slices.Contains(mask, "*"):
+		fields, data = msg.fields, msg.contents
+	default:
+		for _, field := range mask {
+			top, _, _ := strings.Cut(field, ".")
+			switch {
+			case top == "contents" && collection == names.Specs:
+				data = msg.contents
+			case !slices.Contains(settable[collection], top):
+				return nil, errorf(invalidArgument, "invalid update mask field %q", field)
+			default:
+				if v, ok := msg.fields[top]; ok {
+					fields[top] = v
+				} else {
+					delete(fields, top)
+				}
+			}
+		}
+	}
+	r.commit(res, fields, data)
+	return render(res, res.current(), false), nil
+}
+
+// replace implements ReplaceArtifact.
+func (r *Registry) replace(name names.Name, body []byte) (any, error) {
+	res, _, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := decode(names.Artifacts, body)
+	if err != nil {
+		return nil, err
+	}
+	r.commit(res, msg.fields, msg.contents)
+	return render(res, res.current(), false), nil
+}
+
+// commit stores new field values of res, as a new revision if a revision
+// field changed.
+func (r *Registry) commit(res *resource, fields map[string]any, data []byte) {
+	now := r.now()
+	cur := res.current()
+	changed := false
+	for _, f := range revisionFields[res.name.Collection()] {
+		if f == "contents" {
+			changed = changed || !bytes.Equal(uncompressed(cur.contents), uncompressed(data))
+		} else {
+			changed = changed || cur.fields[f] != fields[f]
+		}
+	}
+	if !changed {
+		cur.fields, cur.contents, cur.updateTime = fields, data, now
+		return
+	}
+	rev := &revision{id: r.nextID(), createTime: now, updateTime: now, fields: fields, contents: data}
+	res.revisions = append([]*revision{rev}, res.revisions...)
+}
+
+// delete implements the Delete methods. Resources with children can only be
+// deleted with force, which deletes the children too.
+func (r *Registry) delete(name names.Name, query url.Values) (any, error) {
+	if name.Revision != "" {
+		return nil, errorf(invalidArgument, "%s: use deleteRevision to delete a revision", name)
+	}
+	res, _, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	children := r.children(res.name)
+	if force, _ := strconv.ParseBool(query.Get("force")); len(children) > 0 && !force {
+		return nil, errorf(failedPrecondition, "%s has %d child resources; delete them first or set force", name, len(children))
+	}
+	for _, child := range children {
+		delete(r.resources, child)
+	}
+	delete(r.resources, name.String())
+	return map[string]any{}, nil
+}
+
+// tagRevision implements TagApiSpecRevision and TagApiDeploymentRevision. The
+// revision is taken from the path or else from the name in the body, and
+// defaults to the current one. A tag names one revision at a time.
+func (r *Registry) tagRevision(name names.Name, body []byte) (any, error) {
+	var req struct {
+		Name string `json:"name"`
+		Tag  string `json:"tag"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, errorf(invalidArgument, "invalid JSON payload: %v", err)
+	}
+	if !tagPattern.MatchString(req.Tag) {
+		return nil, errorf(invalidArgument, "invalid tag %q: must match %s", req.Tag, tagPattern)
+	}
+	if name.Revision == "" && req.Name != "" {
+		if n, err := names.Parse(req.Name); err == nil {
+			name.Revision = n.Revision
+		}
+	}
+	res, rev, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range res.revisions {
+		other.tags = slices.DeleteFunc(other.tags, func(t string) bool { return t == req.Tag })
+	}
+	rev.tags = append(rev.tags, req.Tag)
+	rev.updateTime = r.now()
+	return render(res, rev, true), nil
+}
+
+// rollback implements RollbackApiSpec and RollbackApiDeployment: the chosen
+// revision is copied to a new current revision.
+func (r *Registry) rollback(name names.Name, body []byte) (any, error) {
+	var req struct {
+		RevisionID string `json:"revisionId"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, errorf(invalidArgument, "invalid JSON payload: %v", err)
+	}
+	if req.RevisionID == "" {
+		return nil, errorf(invalidArgument, "revisionId is required")
+	}
+	name.Revision = req.RevisionID
+	res, old, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	rev := &revision{id: r.nextID(), createTime: now, updateTime: now, fields: maps.Clone(old.fields), contents: old.contents}
+	res.revisions = append([]*revision{rev}, res.revisions...)
+	return render(res, rev, true), nil
+}
+
+// deleteRevision implements DeleteApiSpecRevision and
+// DeleteApiDeploymentRevision and returns the resource's current revision
+// afterwards. The only revision cannot be deleted.
+func (r *Registry) deleteRevision(name names.Name) (any, error) {
+	if name.Revision == "" {
+		return nil, errorf(invalidArgument, "%s: the name must include a revision ID", name)
+	}
+	res, rev, err := r.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.revisions) == 1 {
+		return nil, errorf(failedPrecondition, "%s: cannot delete the only revision", name)
+	}
+	res.revisions = slices.DeleteFunc(res.revisions, func(other *revision) bool { return other == rev })
+	return render(res, res.current(), false), nil
+}
+
+// camelCase converts an update mask path such as display_name to the JSON
+// field name.
+func camelCase(field string) string {
+	parts := strings.Split(field, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// Seed adds a small sample registry to project and location, for demos: two
+// APIs with versions, a spec and a deployment with two revisions each, a tag
+// and an artifact. Against a new registry, the spec revisions are 00000001
+// and 00000002 (tagged stable), and the deployment revisions 00000003 and
+// 00000004.
+func (r *Registry) Seed(project, location string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	loc := names.Name{Segments: []names.Segment{{Collection: names.Projects, ID: project}, {Collection: names.Locations, ID: location}}}
+	child := func(parent names.Name, collection, id string) names.Name {
+		return names.Name{Segments: append(slices.Clone(parent.Segments), names.Segment{Collection: collection, ID: id})}
+	}
+	labels := func(kv ...string) map[string]any {
+		m := map[string]any{}
+		for i := 0; i+1 < len(kv); i += 2 {
+			m[kv[i]] = kv[i+1]
+		}
+		return m
+	}
+	const openapi = "application/x.openapi+yaml;version=3"
+
+	petstore := child(loc, names.Apis, "petstore")
+	r.insert(petstore, message{fields: map[string]any{
+		"displayName":  "Swagger Petstore",
+		"description":  "Sample API for managing pets in a pet store.",
+		"availability": "GENERAL",
+		"labels":       labels("team", "pets", "tier", "public"),
+	}})
+	v1 := child(petstore, names.Versions, "v1")
+	r.insert(v1, message{fields: map[string]any{"displayName": "v1", "state": "PRODUCTION"}})
+	spec := child(v1, names.Specs, "openapi")
+	res := r.insert(spec, message{
+		fields:   map[string]any{"filename": "openapi.yaml", "mimeType": openapi},
+		contents: []byte(petstoreSpec("1.0.0")),
+	})
+	first := res.current().id
+	r.commit(res, maps.Clone(res.current().fields), []byte(petstoreSpec("1.0.1")))
+	res.current().tags = []string{"stable"}
+	deployment := r.insert(child(petstore, names.Deployments, "prod"), message{fields: map[string]any{
+		"displayName":     "Production",
+		"endpointUri":     "https://petstore.example.com/v1",
+		"apiSpecRevision": fmt.Sprintf("%s@%s", spec, first),
+		"labels":          labels("env", "prod"),
+	}})
+	fields := maps.Clone(deployment.current().fields)
+	fields["apiSpecRevision"] = fmt.Sprintf("%s@%s", spec, res.current().id)
+	r.commit(deployment, fields, nil)
+
+	payments := child(loc, names.Apis, "payments")
+	r.insert(payments, message{fields: map[string]any{
+		"displayName":  "Payments",
+		"description":  "Card payments and refunds.",
+		"availability": "PREVIEW",
+		"labels":       labels("team", "payments", "tier", "internal"),
+	}})
+	r.insert(child(payments, names.Versions, "v1alpha1"), message{fields: map[string]any{"state": "DEVELOPMENT"}})
+
+	r.insert(child(loc, names.Artifacts, "style-guide"), message{
+		fields:   map[string]any{"mimeType": "text/markdown"},
+		contents: []byte("# API style guide\n\nUse plural resource names and standard methods.\n"),
+	})
+}
+
+func petstoreSpec(version string) string {
+	return `openapi: 3.0.0
+info:
+  title: Swagger Petstore
+  version: ` + version + `
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: A list of pets.
+  /pets/{petId}:
+    get:
+      operationId: showPetById
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: The pet.
+`
+}
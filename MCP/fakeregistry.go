@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/fake"
+)
+
+// runFakeRegistry serves an in-memory registry, for trying the server out
+// without access to a real one:
+//
+//	mcp-server fake-registry -port 9090 &
+//	API_BASE_URL=http://localhost:9090 mcp-server
+func runFakeRegistry(args []string) {
+	flags := flag.NewFlagSet("fake-registry", flag.ExitOnError)
+	port := flags.String("port", os.Getenv("PORT"), "port to listen on (default $PORT or 8080)")
+	token := flags.String("token", "", "bearer token requests must carry; empty accepts any request")
+	empty := flags.Bool("empty", false, "start without the sample APIs")
+	project := flags.String("project", "demo", "project of the sample APIs")
+	flags.Parse(args)
+	if *port == "" {
+		*port = "8080"
+	}
+
+	var opts []fake.Option
+	if *token != "" {
+		opts = append(opts, fake.WithToken(*token))
+	}
+	registry := fake.New(opts...)
+	if !*empty {
+		registry.Seed(*project, config.DefaultLocationID)
+	}
+
+	addr := net.JoinHostPort("0.0.0.0", *port)
+	httpServer := &http.Server{Addr: addr, Handler: registry}
+	go func() {
+		log.Printf("Fake registry listening on %s", addr)
+		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatalf("Fake registry error: %v", err)
+		}
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	httpServer.Shutdown(ctx)
+}
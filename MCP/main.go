@@ -2,24 +2,51 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/metrics"
+	"github.com/registry-api/mcp-server/prompts"
+	"github.com/registry-api/mcp-server/resources"
+	"github.com/registry-api/mcp-server/telemetry"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fake-registry" {
+		runFakeRegistry(os.Args[2:])
+		return
+	}
+
 	cfg, err := config.LoadAPIConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	shutdownTracing, err := telemetry.Setup(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Tracing shutdown error: %v", err)
+		}
+	}()
+
 	// Check transport environment variable (both uppercase and lowercase)
 	transport := os.Getenv("TRANSPORT")
 	if transport == "" {
@@ -44,39 +71,11 @@ func main() {
 		}
 		
 		log.Printf("Running in %s mode on port %s", transport, port)
+		if cfg.TokenSource != nil && cfg.BaseURL == "" {
+			log.Printf("The token source is not used: in %s mode it is only sent to the registry named by the API_BASE_URL environment variable, which is not set", transport)
+		}
 
-		mux := http.NewServeMux()
-		mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
-			// Read headers for dynamic config
-			apiCfg := &config.APIConfig{
-				BaseURL:     r.Header.Get("API_BASE_URL"),
-				BearerToken: r.Header.Get("BEARER_TOKEN"),
-				APIKey:      r.Header.Get("API_KEY"),
-				BasicAuth:   r.Header.Get("BASIC_AUTH"),
-			}
-
-			if apiCfg.BaseURL == "" {
-				http.Error(w, "Missing API_BASE_URL header", http.StatusBadRequest)
-				return
-			}
-
-			log.Printf("Incoming HTTP request - BaseURL: %s", apiCfg.BaseURL)
-
-			// Create MCP server for this request
-			mcpSrv := createMCPServer(apiCfg, transport)
-			handler := server.NewStreamableHTTPServer(mcpSrv, server.WithHTTPContextFunc(
-				func(ctx context.Context, req *http.Request) context.Context {
-					return context.WithValue(ctx, "apiConfig", apiCfg)
-				},
-			))
-
-			handler.ServeHTTP(w, r)
-		})
-
-		mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte(`{"status":"ok"}`))
-		})
+		mux, streamable := newHTTPHandler(cfg, transport)
 
 		addr := net.JoinHostPort("0.0.0.0", port)
 		httpServer := &http.Server{Addr: addr, Handler: mux}
@@ -105,9 +104,13 @@ func main() {
 
 		<-sigChan
 		log.Println("Shutdown signal received")
+		logCacheStats(cfg)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
+		if err := streamable.Shutdown(ctx); err != nil {
+			log.Printf("MCP server shutdown error: %v", err)
+		}
 		if err := httpServer.Shutdown(ctx); err != nil {
 			log.Printf("Shutdown error: %v", err)
 		} else {
@@ -118,7 +121,7 @@ func main() {
 
 	// STDIO Mode - default when no transport or transport is "stdio"
 	log.Println("Running in STDIO mode")
-	mcp := createMCPServer(cfg, "STDIO")
+	mcp := createMCPServer(cfg, "STDIO", nil)
 	go func() {
 		if err := server.ServeStdio(mcp); err != nil {
 			log.Fatalf("STDIO error: %v", err)
@@ -126,11 +129,158 @@ func main() {
 	}()
 	<-sigChan
 	log.Println("Received shutdown signal. Exiting STDIO mode.")
+	logCacheStats(cfg)
+}
+
+// logCacheStats logs the response cache's counters, if caching is enabled.
+func logCacheStats(cfg *config.APIConfig) {
+	if cfg.Cache == nil {
+		return
+	}
+	s := cfg.Cache.Stats()
+	log.Printf("Response cache: %d hits, %d misses, %d revalidated, %d invalidated, %d evicted, %d entries",
+		s.Hits, s.Misses, s.Revalidations, s.Invalidations, s.Evictions, s.Entries)
 }
 
-func createMCPServer(cfg *config.APIConfig, mode string) *server.MCPServer {
+// newHTTPHandler returns the handler serving /mcp, /metrics and the health
+// check in HTTP(S) mode, and the MCP transport behind it, which must be shut
+// down with the server.
+func newHTTPHandler(cfg *config.APIConfig, transport string) (*http.ServeMux, *server.StreamableHTTPServer) {
+	// One MCP server handles every request, so sessions, their SSE streams
+	// and notifications survive between requests. The configuration taken
+	// from the headers of the initialize request is bound to the session,
+	// and travels in the context of each request.
+	sessions := &sessionConfigs{}
+	mcpSrv := createMCPServer(cfg, transport, sessions)
+	streamable := server.NewStreamableHTTPServer(mcpSrv,
+		server.WithStateful(true),
+		server.WithSessionIdleTTL(cfg.SessionIdleTimeout),
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		apiCfg, status, err := requestConfig(cfg, sessions.get(r.Header.Get(server.HeaderKeySessionID)), r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		log.Printf("Incoming HTTP request - BaseURL: %s", apiCfg.BaseURL)
+		ctx := telemetry.Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		streamable.ServeHTTP(w, r.WithContext(config.NewContext(ctx, apiCfg)))
+	})
+
+	mux.Handle("/metrics", metrics.Handler(cfg.Cache))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if cfg.Cache == nil {
+			w.Write([]byte(`{"status":"ok"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"status": "ok", "cache": cfg.Cache.Stats()})
+	})
+	return mux, streamable
+}
+
+// requestConfig derives the configuration of an HTTP request from its headers
+// and the server's configuration cfg. Within a session, bound is the
+// configuration of the request that initialised it: headers the request omits
+// are taken from it, and the tool selection can only be narrowed further. On
+// error requestConfig also returns the HTTP status to answer with.
+func requestConfig(cfg, bound *config.APIConfig, r *http.Request) (*config.APIConfig, int, error) {
+	base := cfg
+	if bound != nil {
+		base = bound
+	}
+	apiCfg := &config.APIConfig{
+		BaseURL:     r.Header.Get("API_BASE_URL"),
+		BearerToken: r.Header.Get("BEARER_TOKEN"),
+		APIKey:      r.Header.Get("API_KEY"),
+		APIKeyName:  cfg.APIKeyName,
+		APIKeyIn:    cfg.APIKeyIn,
+		BasicAuth:   r.Header.Get("BASIC_AUTH"),
+		Timeout:     cfg.Timeout,
+		Retry:       cfg.Retry,
+
+		DefaultProject:  headerOr(r, "DEFAULT_PROJECT", base.DefaultProject),
+		DefaultLocation: headerOr(r, "DEFAULT_LOCATION", base.DefaultLocation),
+		AllowedProjects: cfg.AllowedProjects,
+		WatchInterval:   cfg.WatchInterval,
+		ToolNaming:      cfg.ToolNaming,
+
+		ConfirmDestructive: cfg.ConfirmDestructive,
+		DryRun:             cfg.DryRun,
+
+		Audit: cfg.Audit,
+		Cache: cfg.Cache,
+
+		SessionIdleTimeout: cfg.SessionIdleTimeout,
+	}
+
+	// Headers can only narrow the server's tool selection.
+	headerFilter, err := config.LoadToolFilter(r.Header.Get)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	apiCfg.ToolFilters = append(slices.Clone(base.ToolFilters), headerFilter)
+
+	// The registry and credentials are taken together, so a request naming
+	// another registry never reuses the session's credentials.
+	if apiCfg.BaseURL == "" && bound != nil {
+		apiCfg.BaseURL = bound.BaseURL
+		apiCfg.BearerToken = bound.BearerToken
+		apiCfg.APIKey = bound.APIKey
+		apiCfg.BasicAuth = bound.BasicAuth
+	}
+	if apiCfg.BaseURL == "" {
+		if r.Header.Get(server.HeaderKeySessionID) != "" {
+			// The session has expired or was never bound; the client must
+			// initialise a new one.
+			return nil, http.StatusNotFound, errors.New("Session not found")
+		}
+		return nil, http.StatusBadRequest, errors.New("Missing API_BASE_URL header")
+	}
+	// The server's own token is only sent to the registry it was configured
+	// for; a caller naming any other registry must bring its credentials.
+	if cfg.BaseURL != "" && strings.TrimSuffix(apiCfg.BaseURL, "/") == strings.TrimSuffix(cfg.BaseURL, "/") {
+		apiCfg.TokenSource = cfg.TokenSource
+	}
+	if apiCfg.DefaultProject != "" {
+		if err := apiCfg.CheckProject(apiCfg.DefaultProject); err != nil {
+			return nil, http.StatusForbidden, fmt.Errorf("Invalid DEFAULT_PROJECT header: %w", err)
+		}
+	}
+	return apiCfg, 0, nil
+}
+
+// headerOr returns the named request header, or def when it is not set.
+func headerOr(r *http.Request, name, def string) string {
+	if v := r.Header.Get(name); v != "" {
+		return v
+	}
+	return def
+}
+
+func createMCPServer(cfg *config.APIConfig, mode string, sessions *sessionConfigs) *server.MCPServer {
+	hooks := &server.Hooks{}
+	countSessions(hooks)
+	if sessions != nil {
+		sessions.register(hooks)
+	}
+	hooks.AddAfterListResources(resources.ListHook(cfg))
+	resources.NewWatcher(cfg).Register(hooks)
+	hooks.AddBeforeCallTool(aliasHook(cfg))
+	toolFilter, toolMiddleware := requestTools(cfg)
+
 	mcp := server.NewMCPServer("Registry API", "0.0.1",
 		server.WithToolCapabilities(true),
+		server.WithToolFilter(toolFilter),
+		server.WithToolHandlerMiddleware(toolMiddleware),
+		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(false),
+		server.WithPromptFilter(prompts.Filter(cfg)),
+		server.WithHooks(hooks),
 		server.WithRecovery(),
 	)
 
@@ -141,5 +291,13 @@ func createMCPServer(cfg *config.APIConfig, mode string) *server.MCPServer {
 		mcp.AddTool(tool.Definition, tool.Handler)
 	}
 
+	for _, template := range resources.GetTemplates(cfg) {
+		mcp.AddResourceTemplate(template.Definition, template.Handler)
+	}
+
+	for _, prompt := range prompts.GetAll(cfg) {
+		mcp.AddPrompt(prompt.Definition, prompt.Handler)
+	}
+
 	return mcp
 }
\ No newline at end of file
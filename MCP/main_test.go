@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/registry-api/mcp-server/config"
+	"golang.org/x/oauth2"
+)
+
+func TestRequestConfigTokenSource(t *testing.T) {
+	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "server-token"})
+	tests := []struct {
+		name       string
+		configured string // The server's API_BASE_URL
+		header     string // The request's API_BASE_URL
+		want       bool
+	}{
+		{"configured registry", "https://registry.example/v1", "https://registry.example/v1", true},
+		{"trailing slash", "https://registry.example/v1/", "https://registry.example/v1", true},
+		{"other registry", "https://registry.example/v1", "https://collector.example/v1", false},
+		{"other path", "https://registry.example/v1", "https://registry.example/v1/proxy", false},
+		{"no configured registry", "", "https://registry.example/v1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.APIConfig{BaseURL: tt.configured, TokenSource: src}
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			r.Header.Set("API_BASE_URL", tt.header)
+			apiCfg, _, err := requestConfig(cfg, nil, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := apiCfg.TokenSource != nil; got != tt.want {
+				t.Errorf("token source attached = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
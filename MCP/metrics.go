@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/metrics"
+	"github.com/registry-api/mcp-server/models"
+)
+
+// withMetrics counts the calls of tool, how long they take and which of them
+// fail.
+func withMetrics(tool models.Tool) models.Tool {
+	name := tool.Definition.Name
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		metrics.ToolCalls.WithLabelValues(name).Inc()
+		metrics.ToolsInFlight.Inc()
+		defer metrics.ToolsInFlight.Dec()
+		start := time.Now()
+
+		ctx, trace := client.WithTrace(ctx)
+		ctx, f := withFailure(ctx)
+		result, err := handler(ctx, request)
+
+		metrics.ToolDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err != nil || (result != nil && result.IsError) || f.code != "" {
+			metrics.ToolErrors.WithLabelValues(name, errorCode(trace, f, err)).Inc()
+		}
+		return result, err
+	}
+	return tool
+}
+
+// failure is where the wrappers of a tool note why they turned a call down.
+type failure struct{ code string }
+
+type failureKey struct{}
+
+// withFailure returns a context in which the wrappers of a tool can note why
+// they turned the call down, reusing the failure of an outer wrapper.
+func withFailure(ctx context.Context) (context.Context, *failure) {
+	if f, ok := ctx.Value(failureKey{}).(*failure); ok {
+		return ctx, f
+	}
+	f := &failure{}
+	return context.WithValue(ctx, failureKey{}, f), f
+}
+
+// noteFailure counts the current tool call as failed with code, a
+// google.rpc.Code name, even if its result is not an error.
+func noteFailure(ctx context.Context, code string) {
+	if f, ok := ctx.Value(failureKey{}).(*failure); ok {
+		f.code = code
+	}
+}
+
+// rejected returns an error result for a call turned down with code before
+// anything was sent to the registry.
+func rejected(ctx context.Context, code, message string) *mcp.CallToolResult {
+	noteFailure(ctx, code)
+	return mcp.NewToolResultError(message)
+}
+
+// errorCode returns the google.rpc.Code name a failed call is counted under:
+// the code it was rejected with, that of its last failed registry request,
+// INTERNAL if its handler returned an error and UNKNOWN otherwise.
+func errorCode(trace *client.Trace, f *failure, err error) string {
+	if f.code != "" {
+		return f.code
+	}
+	calls := trace.Calls()
+	for i := len(calls) - 1; i >= 0; i-- {
+		if err := calls[i].Err; err != nil {
+			return client.ErrorCode(err)
+		}
+	}
+	if err != nil {
+		return "INTERNAL"
+	}
+	return "UNKNOWN"
+}
+
+// withArgumentChecks counts the calls tool fails without sending anything as
+// INVALID_ARGUMENT: the generated handlers only check their arguments before
+// calling the registry.
+func withArgumentChecks(tool models.Tool) models.Tool {
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		traced, trace := client.WithTrace(ctx)
+		result, err := handler(traced, request)
+		if err == nil && result != nil && result.IsError && len(trace.Calls()) == 0 {
+			noteFailure(ctx, "INVALID_ARGUMENT")
+		}
+		return result, err
+	}
+	return tool
+}
+
+// countSessions keeps metrics.Sessions at the number of open sessions.
+func countSessions(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		metrics.Sessions.Inc()
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		metrics.Sessions.Dec()
+	})
+}
@@ -0,0 +1,123 @@
+// Package metrics holds the server's Prometheus metrics: tool calls, the
+// requests they send to the registry, and MCP sessions. HTTP(S) mode serves
+// them at /metrics.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/registry-api/mcp-server/cache"
+)
+
+var (
+	// ToolCalls counts tool calls by tool name.
+	ToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_tool_calls_total",
+		Help: "Tool calls, by tool.",
+	}, []string{"tool"})
+
+	// ToolErrors counts failed tool calls by tool name and google.rpc.Code
+	// name, e.g. NOT_FOUND.
+	ToolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_tool_errors_total",
+		Help: "Tool calls that failed, by tool and google.rpc.Code name.",
+	}, []string{"tool", "code"})
+
+	// ToolDuration observes how long tool calls take, including every
+	// registry request they send.
+	ToolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_tool_call_duration_seconds",
+		Help:    "Duration of tool calls, by tool.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"tool"})
+
+	// ToolsInFlight is the number of tool calls being handled.
+	ToolsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mcp_tool_calls_in_flight",
+		Help: "Tool calls being handled.",
+	})
+
+	// Sessions is the number of open MCP sessions.
+	Sessions = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mcp_sessions_active",
+		Help: "Open MCP sessions.",
+	})
+
+	// UpstreamDuration observes each request sent to the registry, retries
+	// included, by HTTP method, route (the path with IDs replaced by "*")
+	// and HTTP status code, or "none" if no response was received.
+	UpstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_upstream_request_duration_seconds",
+		Help:    "Latency of requests to the registry, by method, route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	// UpstreamInFlight is the number of requests to the registry awaiting a
+	// response.
+	UpstreamInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mcp_upstream_requests_in_flight",
+		Help: "Requests to the registry awaiting a response.",
+	})
+
+	// UpstreamRetries counts requests to the registry that were retried, by
+	// HTTP method and route.
+	UpstreamRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_upstream_retries_total",
+		Help: "Retried requests to the registry, by method and route.",
+	}, []string{"method", "route"})
+)
+
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ToolCalls, ToolErrors, ToolDuration, ToolsInFlight, Sessions,
+		UpstreamDuration, UpstreamInFlight, UpstreamRetries,
+	)
+}
+
+// StatusCode returns the code label of an upstream response with the given
+// HTTP status, 0 meaning there was none.
+func StatusCode(status int) string {
+	if status == 0 {
+		return "none"
+	}
+	return strconv.Itoa(status)
+}
+
+// Handler serves the metrics in the Prometheus text format, along with the
+// counters of the response cache rc, if not nil.
+func Handler(rc *cache.Cache) http.Handler {
+	gatherers := prometheus.Gatherers{registry}
+	if rc != nil {
+		local := prometheus.NewRegistry()
+		local.MustRegister(cacheCollectors(rc)...)
+		gatherers = append(gatherers, local)
+	}
+	return promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+}
+
+// cacheCollectors reports the counters of rc.
+func cacheCollectors(rc *cache.Cache) []prometheus.Collector {
+	counter := func(name, help string, value func(cache.Stats) int64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, func() float64 {
+			return float64(value(rc.Stats()))
+		})
+	}
+	return []prometheus.Collector{
+		counter("mcp_cache_hits_total", "Registry reads answered from the cache.", func(s cache.Stats) int64 { return s.Hits }),
+		counter("mcp_cache_misses_total", "Registry reads sent upstream.", func(s cache.Stats) int64 { return s.Misses }),
+		counter("mcp_cache_revalidations_total", "Expired cache entries found unchanged upstream.", func(s cache.Stats) int64 { return s.Revalidations }),
+		counter("mcp_cache_invalidations_total", "Cache entries dropped because of a change.", func(s cache.Stats) int64 { return s.Invalidations }),
+		counter("mcp_cache_evictions_total", "Cache entries dropped to make room.", func(s cache.Stats) int64 { return s.Evictions }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "mcp_cache_entries", Help: "Responses in the cache."}, func() float64 {
+			return float64(rc.Stats().Entries)
+		}),
+	}
+}
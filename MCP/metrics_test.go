@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/fake"
+	"github.com/registry-api/mcp-server/metrics"
+	"golang.org/x/oauth2"
+)
+
+// scrape returns the samples served at url, keyed by series, e.g.
+// `mcp_tool_calls_total{tool="get_api"}`.
+func scrape(t *testing.T, url string) map[string]float64 {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := map[string]float64{}
+	for _, line := range strings.Split(string(body), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("sample %q: %v", line, err)
+		}
+		samples[line[:i]] = v
+	}
+	return samples
+}
+
+const listRoute = `method="GET",route="/v1/projects/*/locations/*/apis"`
+
+func TestMetrics(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	f := fake.New()
+	f.Seed("demo", "global")
+	registry := httptest.NewServer(&flaky{registry: f})
+	defer registry.Close()
+
+	cfg := testConfig()
+	cfg.Retry.MaxAttempts = 2
+	cfg.Retry.InitialBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = time.Millisecond
+	mux, streamable := newHTTPHandler(cfg, "HTTP")
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	defer streamable.Shutdown(context.Background())
+
+	before := scrape(t, srv.URL+"/metrics")
+	tr, err := transport.NewStreamableHTTP(srv.URL+"/mcp", transport.WithHTTPHeaders(map[string]string{
+		"API_BASE_URL": registry.URL,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewClient(tr)
+	defer c.Close()
+	initialize(t, c)
+
+	// The first registry request fails with 503 and is retried.
+	if res := callTool(t, c, "list_apis", demo(map[string]any{}), nil); res.IsError {
+		t.Fatalf("list_apis failed: %s", text(res))
+	}
+	if res := callTool(t, c, "get_api", demo(map[string]any{"api": "petstore"}), nil); res.IsError {
+		t.Fatalf("get_api failed: %s", text(res))
+	}
+	if res := callTool(t, c, "get_api", demo(map[string]any{"api": "missing"}), nil); !res.IsError {
+		t.Fatal("get_api of a missing API succeeded")
+	}
+
+	after := scrape(t, srv.URL+"/metrics")
+	for series, want := range map[string]float64{
+		`mcp_tool_calls_total{tool="list_apis"}`:                                    1,
+		`mcp_tool_calls_total{tool="get_api"}`:                                      2,
+		`mcp_tool_errors_total{code="NOT_FOUND",tool="get_api"}`:                    1,
+		`mcp_tool_errors_total{code="UNAVAILABLE",tool="list_apis"}`:                0,
+		`mcp_tool_call_duration_seconds_count{tool="get_api"}`:                      2,
+		`mcp_upstream_retries_total{` + listRoute + `}`:                             1,
+		`mcp_upstream_request_duration_seconds_count{code="503",` + listRoute + `}`: 1,
+		`mcp_upstream_request_duration_seconds_count{code="200",` + listRoute + `}`: 1,
+		`mcp_sessions_active`: 1,
+	} {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s went up by %v, want %v", series, got, want)
+		}
+	}
+	if after["mcp_tool_calls_in_flight"] != 0 || after["mcp_upstream_requests_in_flight"] != 0 {
+		t.Errorf("calls still in flight: %v tool calls, %v registry requests",
+			after["mcp_tool_calls_in_flight"], after["mcp_upstream_requests_in_flight"])
+	}
+
+	// Closing the client ends its session.
+	c.Close()
+	deadline := time.Now().Add(time.Second)
+	for scrape(t, srv.URL+"/metrics")["mcp_sessions_active"] != before["mcp_sessions_active"] {
+		if time.Now().After(deadline) {
+			t.Fatal("mcp_sessions_active did not drop after the session was closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// brokenSource never has a token.
+type brokenSource struct{}
+
+func (brokenSource) Token() (*oauth2.Token, error) {
+	return nil, errors.New("refresh token revoked")
+}
+
+// TestToolErrorCodes checks the code calls that fail without an error
+// response from the registry are counted under.
+func TestToolErrorCodes(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	up := &upstream{}
+	up.reset()
+	registry := httptest.NewServer(up)
+	defer registry.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	tests := []struct {
+		name   string
+		config func(*config.APIConfig)
+		tool   string
+		args   map[string]any
+		want   string
+	}{
+		{
+			name: "missing argument",
+			tool: "get_api",
+			args: demo(map[string]any{}),
+			want: "INVALID_ARGUMENT",
+		},
+		{
+			name: "invalid name",
+			tool: "get_api",
+			args: map[string]any{"name": "projects/demo/apis/petstore"},
+			want: "INVALID_ARGUMENT",
+		},
+		{
+			name:   "project not allowed",
+			config: func(cfg *config.APIConfig) { cfg.AllowedProjects = []string{"other"} },
+			tool:   "get_api",
+			args:   demo(map[string]any{"api": "petstore"}),
+			want:   "PERMISSION_DENIED",
+		},
+		{
+			name:   "invalid confirmation",
+			config: func(cfg *config.APIConfig) { cfg.ConfirmDestructive = true },
+			tool:   "delete_api",
+			args:   demo(map[string]any{"api": "petstore", "confirm": "forged"}),
+			want:   "FAILED_PRECONDITION",
+		},
+		{
+			name:   "no access token",
+			config: func(cfg *config.APIConfig) { cfg.TokenSource = brokenSource{} },
+			tool:   "get_api",
+			args:   demo(map[string]any{"api": "petstore"}),
+			want:   "UNAUTHENTICATED",
+		},
+		{
+			name:   "registry unreachable",
+			config: func(cfg *config.APIConfig) { cfg.BaseURL = down.URL },
+			tool:   "get_api",
+			args:   demo(map[string]any{"api": "petstore"}),
+			want:   "UNAVAILABLE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.BaseURL = registry.URL
+			if tt.config != nil {
+				tt.config(cfg)
+			}
+			c := serveStdio(t, cfg)
+			series := `mcp_tool_errors_total{code="` + tt.want + `",tool="` + tt.tool + `"}`
+			before := counter(t, series)
+			if res := callTool(t, c, tt.tool, tt.args, nil); !res.IsError {
+				t.Fatalf("%s succeeded: %s", tt.tool, text(res))
+			}
+			if got := counter(t, series) - before; got != 1 {
+				t.Errorf("%s went up by %v, want 1", series, got)
+			}
+		})
+	}
+}
+
+// TestToolErrorCodesFiltered checks that calls of a tool the connection's
+// headers disable are counted as PERMISSION_DENIED.
+func TestToolErrorCodesFiltered(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	mcpURL, registryURL := sessionServer(t, time.Minute)
+	tr, err := transport.NewStreamableHTTP(mcpURL, transport.WithHTTPHeaders(map[string]string{
+		"API_BASE_URL": registryURL,
+		"READ_ONLY":    "true",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewClient(tr)
+	defer c.Close()
+	initialize(t, c)
+
+	series := `mcp_tool_errors_total{code="PERMISSION_DENIED",tool="delete_api"}`
+	before := counter(t, series)
+	if res := callTool(t, c, "delete_api", demo(map[string]any{"api": "petstore"}), nil); !res.IsError {
+		t.Fatalf("delete_api succeeded on a read-only connection: %s", text(res))
+	}
+	if got := counter(t, series) - before; got != 1 {
+		t.Errorf("%s went up by %v, want 1", series, got)
+	}
+}
+
+// counter returns the current value of series.
+func counter(t *testing.T, series string) float64 {
+	t.Helper()
+	srv := httptest.NewServer(metrics.Handler(nil))
+	defer srv.Close()
+	return scrape(t, srv.URL)[series]
+}
@@ -0,0 +1,196 @@
+// Package names parses and formats registry resource names such as
+// projects/p/locations/l/apis/a/versions/v/specs/s@revision.
+package names
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Collection keywords of the registry resource hierarchy.
+const (
+	Projects    = "projects"
+	Locations   = "locations"
+	Apis        = "apis"
+	Versions    = "versions"
+	Specs       = "specs"
+	Deployments = "deployments"
+	Artifacts   = "artifacts"
+)
+
+// Error reports an invalid resource name or ID.
+type Error struct {
+	msg string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+func errorf(format string, args ...any) error {
+	return &Error{msg: fmt.Sprintf(format, args...)}
+}
+
+// Wildcard stands for every resource of a collection in the parent of a list
+// request, e.g. projects/p/locations/l/apis/-/deployments (AIP-159).
+const Wildcard = "-"
+
+// idPattern is the AIP-122 resource ID format used by the registry: up to 63
+// lowercase letters, digits and hyphens, starting and ending with a letter or
+// digit. Revision IDs and tags follow the same format.
+var idPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ValidateID checks the ID of a resource in collection. Spec and deployment
+// IDs may carry an @revision or @tag suffix.
+func ValidateID(collection, id string) error {
+	if id == Wildcard {
+		return nil
+	}
+	kind := strings.TrimSuffix(collection, "s")
+	if collection == Specs || collection == Deployments {
+		if before, after, ok := strings.Cut(id, "@"); ok {
+			if err := checkID(kind, before); err != nil {
+				return err
+			}
+			return checkID(kind+" revision", after)
+		}
+	}
+	return checkID(kind, id)
+}
+
+func checkID(kind, id string) error {
+	if id == "" {
+		return errorf("empty %s ID", kind)
+	}
+	if !idPattern.MatchString(id) {
+		return errorf("invalid %s ID %q: must be 1-63 lowercase letters, digits or hyphens, starting and ending with a letter or digit", kind, id)
+	}
+	return nil
+}
+
+// children lists the collections allowed below each collection; "" is the
+// root.
+var children = map[string][]string{
+	"":        {Projects},
+	Projects:  {Locations},
+	Locations: {Apis, Artifacts},
+	Apis:      {Versions, Deployments},
+	Versions:  {Specs},
+}
+
+// Segment is one collection/ID pair of a resource name.
+type Segment struct {
+	Collection string
+	ID         string
+}
+
+// Name is a parsed registry resource name.
+type Name struct {
+	Segments []Segment
+	// Revision is the revision ID or tag following "@" in the last segment.
+	// Only specs and deployments have revisions.
+	Revision string
+}
+
+// Parse parses and validates a resource name. A leading "/" is ignored.
+func Parse(name string) (Name, error) {
+	var n Name
+	parts := strings.Split(strings.TrimPrefix(name, "/"), "/")
+	if name == "" || len(parts)%2 != 0 {
+		return n, errorf("invalid resource name %q: expected collection/id pairs such as projects/p/locations/l", name)
+	}
+	parent := ""
+	for i := 0; i < len(parts); i += 2 {
+		collection, id := parts[i], parts[i+1]
+		if !allowed(parent, collection) {
+			if parent == "" {
+				return n, errorf("invalid resource name %q: must start with projects/", name)
+			}
+			return n, errorf("invalid resource name %q: %q cannot follow %q; expected %s", name, collection, parent, strings.Join(children[parent], " or "))
+		}
+		if i == len(parts)-2 && (collection == Specs || collection == Deployments) {
+			if before, after, ok := strings.Cut(id, "@"); ok {
+				if err := checkID(strings.TrimSuffix(collection, "s")+" revision", after); err != nil {
+					return n, errorf("invalid resource name %q: %v", name, err)
+				}
+				id, n.Revision = before, after
+			}
+		}
+		if strings.Contains(id, "@") {
+			return n, errorf("invalid resource name %q: only the last spec or deployment ID may carry an @revision suffix", name)
+		}
+		if err := ValidateID(collection, id); err != nil {
+			return n, errorf("invalid resource name %q: %v", name, err)
+		}
+		n.Segments = append(n.Segments, Segment{Collection: collection, ID: id})
+		parent = collection
+	}
+	return n, nil
+}
+
+// Children returns the collections allowed directly below collection.
+func Children(collection string) []string {
+	return children[collection]
+}
+
+func allowed(parent, collection string) bool {
+	for _, c := range children[parent] {
+		if c == collection {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats n as a resource name.
+func (n Name) String() string {
+	var b strings.Builder
+	for i, s := range n.Segments {
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		b.WriteString(s.Collection)
+		b.WriteByte('/')
+		b.WriteString(s.ID)
+	}
+	if n.Revision != "" {
+		b.WriteByte('@')
+		b.WriteString(n.Revision)
+	}
+	return b.String()
+}
+
+// Collection returns the collection of the named resource, e.g. "specs".
+func (n Name) Collection() string {
+	if len(n.Segments) == 0 {
+		return ""
+	}
+	return n.Segments[len(n.Segments)-1].Collection
+}
+
+// ID returns the ID of the named resource within collection, or "" if the
+// name does not include that collection.
+func (n Name) ID(collection string) string {
+	for _, s := range n.Segments {
+		if s.Collection == collection {
+			return s.ID
+		}
+	}
+	return ""
+}
+
+// Parent returns the name of the resource containing n, without revision.
+func (n Name) Parent() Name {
+	if len(n.Segments) == 0 {
+		return n
+	}
+	return Name{Segments: n.Segments[:len(n.Segments)-1]}
+}
+
+// Spec returns the name of a spec, with an optional revision.
+func Spec(project, location, api, version, spec, revision string) Name {
+	return Name{Segments: []Segment{
+		{Projects, project}, {Locations, location}, {Apis, api}, {Versions, version}, {Specs, spec},
+	}, Revision: revision}
+}
@@ -0,0 +1,79 @@
+package names
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const spec = "projects/p/locations/l/apis/a/versions/v/specs/s"
+	tests := []struct {
+		name     string
+		in       string
+		want     string // Formatted name
+		revision string
+		err      string // Substring of the error
+	}{
+		{name: "project", in: "projects/p", want: "projects/p"},
+		{name: "location", in: "projects/p/locations/global", want: "projects/p/locations/global"},
+		{name: "spec", in: spec, want: spec},
+		{name: "deployment", in: "projects/p/locations/l/apis/a/deployments/d", want: "projects/p/locations/l/apis/a/deployments/d"},
+		{name: "artifact", in: "projects/p/locations/l/artifacts/style-guide", want: "projects/p/locations/l/artifacts/style-guide"},
+		{name: "leading slash", in: "/projects/p/locations/l", want: "projects/p/locations/l"},
+		{name: "wildcard", in: "projects/p/locations/l/apis/-", want: "projects/p/locations/l/apis/-"},
+		{name: "spec revision", in: spec + "@00000001", want: spec + "@00000001", revision: "00000001"},
+		{name: "deployment tag", in: "projects/p/locations/l/apis/a/deployments/d@stable", want: "projects/p/locations/l/apis/a/deployments/d@stable", revision: "stable"},
+
+		{name: "empty", in: "", err: "expected collection/id pairs"},
+		{name: "odd segments", in: "projects/p/locations", err: "expected collection/id pairs"},
+		{name: "trailing slash", in: "projects/p/locations/l/", err: "expected collection/id pairs"},
+		{name: "trailing slash after collection", in: "projects/p/locations/", err: "empty location ID"},
+		{name: "empty ID", in: "projects//locations/l", err: "empty project ID"},
+		{name: "empty revision", in: spec + "@", err: "empty spec revision ID"},
+		{name: "invalid ID", in: "projects/P", err: `invalid project ID "P"`},
+		{name: "invalid revision", in: spec + "@Stable", err: `invalid spec revision ID "Stable"`},
+		{name: "not a project", in: "locations/l/projects/p", err: "must start with projects/"},
+		{name: "wrong order", in: "projects/p/apis/a/locations/l", err: `"apis" cannot follow "projects"; expected locations`},
+		{name: "wrong parent", in: "projects/p/locations/l/apis/a/specs/s", err: `"specs" cannot follow "apis"; expected versions or deployments`},
+		{name: "unknown collection", in: "projects/p/locations/l/things/t", err: `"things" cannot follow "locations"`},
+		{name: "revision of a version", in: "projects/p/locations/l/apis/a/versions/v@1", err: "only the last spec or deployment ID"},
+		{name: "revision of an API", in: "projects/p/locations/l/apis/a@1/versions/v", err: "only the last spec or deployment ID"},
+		{name: "revision of an artifact", in: "projects/p/locations/l/artifacts/x@1", err: "only the last spec or deployment ID"},
+		{name: "revision not last", in: "projects/p/locations/l/apis/a/deployments/d@1/specs/s", err: "only the last spec or deployment ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.in)
+			if tt.err != "" {
+				var nameErr *Error
+				if err == nil || !errors.As(err, &nameErr) || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Parse(%q) = %v, %v; want an error containing %q", tt.in, n, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if n.String() != tt.want || n.Revision != tt.revision {
+				t.Errorf("Parse(%q) = %s with revision %q, want %s with revision %q", tt.in, n, n.Revision, tt.want, tt.revision)
+			}
+		})
+	}
+}
+
+func TestName(t *testing.T) {
+	n := Spec("p", "l", "a", "v", "s", "00000002")
+	if got := n.Collection(); got != Specs {
+		t.Errorf("Collection() = %q, want %q", got, Specs)
+	}
+	if got := n.ID(Versions); got != "v" {
+		t.Errorf("ID(versions) = %q, want v", got)
+	}
+	if got := n.ID(Deployments); got != "" {
+		t.Errorf("ID(deployments) = %q, want none", got)
+	}
+	if got, want := n.Parent().String(), "projects/p/locations/l/apis/a/versions/v"; got != want {
+		t.Errorf("Parent() = %s, want %s", got, want)
+	}
+}
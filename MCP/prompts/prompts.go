@@ -0,0 +1,354 @@
+// Package prompts provides MCP prompts for common registry workflows. Each
+// prompt fetches the relevant registry state up front so the assistant starts
+// from the same facts every time.
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+	"github.com/registry-api/mcp-server/resources"
+)
+
+// maxPortfolio caps the number of APIs and deployments gathered for a
+// portfolio summary.
+const maxPortfolio = 200
+
+// Prompt pairs a prompt definition with the handler rendering it.
+type Prompt struct {
+	Definition mcp.Prompt
+	Handler    server.PromptHandlerFunc
+}
+
+// GetAll returns the registry prompts.
+func GetAll(cfg *config.APIConfig) []Prompt {
+	c := client.New(cfg)
+	return []Prompt{
+		reviewAPISpec(cfg, c),
+		summarizeAPIPortfolio(cfg, c),
+		prepareDeprecationNotice(cfg, c),
+		compareSpecRevisions(cfg, c),
+	}
+}
+
+func reviewAPISpec(cfg *config.APIConfig, c *client.Client) Prompt {
+	return Prompt{
+		Definition: mcp.NewPrompt("review-api-spec",
+			mcp.WithPromptDescription("Review an API spec for correctness, consistency and API design best practices."),
+			scopeArguments("api", "version", "spec"),
+		),
+		Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			a, err := arguments(ctx, cfg, request, "api", "version", "spec")
+			if err != nil {
+				return nil, err
+			}
+			version, err := c.GetApiVersion(ctx, a["project"], a["location"], a["api"], a["version"])
+			if err != nil {
+				return nil, err
+			}
+			spec, err := c.GetApiSpec(ctx, a["project"], a["location"], a["api"], a["version"], a["spec"])
+			if err != nil {
+				return nil, err
+			}
+			contents, err := specContents(ctx, c, spec.Name, a["project"], a["location"], a["api"], a["version"], a["spec"])
+			if err != nil {
+				return nil, err
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "Review the API spec %s (version state: %s).\n\n", spec.Name, orUnset(version.State))
+			b.WriteString("Check the spec for:\n")
+			b.WriteString("- errors and inconsistencies, such as undefined references or mismatched types\n")
+			b.WriteString("- naming, pagination and error-handling conventions\n")
+			b.WriteString("- missing descriptions, examples and security requirements\n")
+			b.WriteString("- anything that would be a breaking change for existing clients\n\n")
+			b.WriteString("List findings by severity and suggest concrete fixes.\n\n")
+			writeJSON(&b, "Version", version)
+			writeJSON(&b, "Spec metadata", specMetadata(spec))
+
+			return mcp.NewGetPromptResult("Review of "+spec.Name, []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+				mcp.NewPromptMessage(mcp.RoleUser, contents),
+			}), nil
+		},
+	}
+}
+
+func summarizeAPIPortfolio(cfg *config.APIConfig, c *client.Client) Prompt {
+	return Prompt{
+		Definition: mcp.NewPrompt("summarize-api-portfolio",
+			mcp.WithPromptDescription("Summarize the APIs registered in a project and location, with their recommended versions and deployments."),
+			scopeArguments(),
+			mcp.WithArgument("filter", mcp.ArgumentDescription("Optional CEL filter restricting the APIs, e.g. labels.team == 'payments'.")),
+		),
+		Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			a, err := arguments(ctx, cfg, request)
+			if err != nil {
+				return nil, err
+			}
+			apis, _, err := client.CollectPages(ctx, &client.ListOptions{Filter: a["filter"]}, maxPortfolio, func(ctx context.Context, opts *client.ListOptions) ([]models.Api, string, error) {
+				page, err := c.ListApis(ctx, a["project"], a["location"], opts)
+				if err != nil {
+					return nil, "", err
+				}
+				return page.Apis, page.Nextpagetoken, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			// The wildcard lists the deployments of every API in one call.
+			deployments, _, err := client.CollectPages(ctx, nil, maxPortfolio, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiDeployment, string, error) {
+				page, err := c.ListApiDeployments(ctx, a["project"], a["location"], names.Wildcard, opts)
+				if err != nil {
+					return nil, "", err
+				}
+				return page.Apideployments, page.Nextpagetoken, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "Summarize the API portfolio of projects/%s/locations/%s.\n\n", a["project"], a["location"])
+			b.WriteString("Group the APIs by owner or domain where labels allow it. For each API give its purpose, availability, recommended version and where it is deployed. ")
+			b.WriteString("Call out APIs without a recommended version, without deployments, or with missing descriptions.\n\n")
+			if len(apis) == maxPortfolio || len(deployments) == maxPortfolio {
+				fmt.Fprintf(&b, "Only the first %d APIs and deployments are included; say so in the summary.\n\n", maxPortfolio)
+			}
+			writeJSON(&b, fmt.Sprintf("APIs (%d)", len(apis)), apis)
+			writeJSON(&b, fmt.Sprintf("Deployments (%d)", len(deployments)), deployments)
+
+			return mcp.NewGetPromptResult("API portfolio summary", []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+			}), nil
+		},
+	}
+}
+
+func prepareDeprecationNotice(cfg *config.APIConfig, c *client.Client) Prompt {
+	return Prompt{
+		Definition: mcp.NewPrompt("prepare-deprecation-notice",
+			mcp.WithPromptDescription("Draft a deprecation notice for an API version, based on its deployments and the API's recommended version."),
+			scopeArguments("api", "version"),
+			mcp.WithArgument("sunset", mcp.ArgumentDescription("Optional date after which the version stops being served.")),
+			mcp.WithArgument("replacement", mcp.ArgumentDescription("Optional version clients should migrate to. Defaults to the API's recommended version.")),
+		),
+		Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			a, err := arguments(ctx, cfg, request, "api", "version")
+			if err != nil {
+				return nil, err
+			}
+			api, err := c.GetApi(ctx, a["project"], a["location"], a["api"])
+			if err != nil {
+				return nil, err
+			}
+			version, err := c.GetApiVersion(ctx, a["project"], a["location"], a["api"], a["version"])
+			if err != nil {
+				return nil, err
+			}
+			deployments, _, err := client.CollectPages(ctx, nil, maxPortfolio, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiDeployment, string, error) {
+				page, err := c.ListApiDeployments(ctx, a["project"], a["location"], a["api"], opts)
+				if err != nil {
+					return nil, "", err
+				}
+				return page.Apideployments, page.Nextpagetoken, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			replacement := a["replacement"]
+			if replacement == "" {
+				replacement = api.Recommendedversion
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "Draft a deprecation notice for %s.\n\n", version.Name)
+			fmt.Fprintf(&b, "Sunset date: %s\n", orUnset(a["sunset"]))
+			fmt.Fprintf(&b, "Replacement version: %s\n\n", orUnset(replacement))
+			b.WriteString("The notice should state what is deprecated and when it stops working, which deployments (endpoints) are affected, ")
+			b.WriteString("what clients must change to migrate, and where to get help. Deployments whose apiSpecRevision belongs to this version are the affected ones. ")
+			b.WriteString("If the replacement version is the deprecated version itself or unknown, point that out instead of guessing.\n\n")
+			writeJSON(&b, "API", api)
+			writeJSON(&b, "Version", version)
+			writeJSON(&b, fmt.Sprintf("Deployments (%d)", len(deployments)), deployments)
+
+			return mcp.NewGetPromptResult("Deprecation notice for "+version.Name, []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+			}), nil
+		},
+	}
+}
+
+func compareSpecRevisions(cfg *config.APIConfig, c *client.Client) Prompt {
+	return Prompt{
+		Definition: mcp.NewPrompt("compare-spec-revisions",
+			mcp.WithPromptDescription("Compare two revisions of an API spec and classify the changes. Defaults to the two most recent revisions."),
+			scopeArguments("api", "version", "spec"),
+			mcp.WithArgument("baseRevision", mcp.ArgumentDescription("Optional older revision ID. Defaults to the previous revision.")),
+			mcp.WithArgument("targetRevision", mcp.ArgumentDescription("Optional newer revision ID. Defaults to the latest revision.")),
+		),
+		Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			a, err := arguments(ctx, cfg, request, "api", "version", "spec")
+			if err != nil {
+				return nil, err
+			}
+			base, target := a["baseRevision"], a["targetRevision"]
+			if base == "" || target == "" {
+				// Revisions are listed newest first.
+				page, err := c.ListApiSpecRevisions(ctx, a["project"], a["location"], a["api"], a["version"], a["spec"], &client.ListOptions{PageSize: 2})
+				if err != nil {
+					return nil, err
+				}
+				var ids []string
+				for _, rev := range page.Apispecs {
+					ids = append(ids, rev.Revisionid)
+				}
+				if target == "" && len(ids) > 0 {
+					target = ids[0]
+				}
+				if base == "" {
+					for _, id := range ids {
+						if id != target {
+							base = id
+							break
+						}
+					}
+				}
+				if base == "" || target == "" {
+					return nil, fmt.Errorf("spec %s has fewer than two revisions to compare", a["spec"])
+				}
+			}
+
+			messages := []mcp.PromptMessage{}
+			revisionNames := map[string]string{}
+			for _, rev := range []string{base, target} {
+				name := names.Spec(a["project"], a["location"], a["api"], a["version"], a["spec"], rev).String()
+				contents, err := specContents(ctx, c, name, a["project"], a["location"], a["api"], a["version"], a["spec"]+"@"+rev)
+				if err != nil {
+					return nil, err
+				}
+				revisionNames[rev] = name
+				messages = append(messages, mcp.NewPromptMessage(mcp.RoleUser, contents))
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "Compare revision %s (base) with revision %s (target) of the attached API spec.\n\n", base, target)
+			fmt.Fprintf(&b, "Base: %s\nTarget: %s\n\n", revisionNames[base], revisionNames[target])
+			b.WriteString("Classify every change as breaking, additive or cosmetic. For breaking changes explain which clients are affected and how they must adapt. ")
+			b.WriteString("Finish with a short changelog entry.")
+
+			return mcp.NewGetPromptResult(fmt.Sprintf("Comparison of %s revisions %s and %s", a["spec"], base, target),
+				append([]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String()))}, messages...),
+			), nil
+		},
+	}
+}
+
+var idDescriptions = map[string]string{
+	"api":     "API ID.",
+	"version": "Version ID.",
+	"spec":    "Spec ID.",
+}
+
+// scopeArguments declares the required project argument, the location
+// argument and the given required resource IDs. Filter relaxes them per
+// request when there are defaults for them.
+func scopeArguments(ids ...string) mcp.PromptOption {
+	return func(p *mcp.Prompt) {
+		mcp.WithArgument("project", mcp.ArgumentDescription("Project ID."), mcp.RequiredArgument())(p)
+		mcp.WithArgument("location", mcp.ArgumentDescription("Location ID."))(p)
+		for _, id := range ids {
+			mcp.WithArgument(id, mcp.ArgumentDescription(idDescriptions[id]), mcp.RequiredArgument())(p)
+		}
+	}
+}
+
+// Filter returns a prompts/list filter making the project and location
+// arguments optional and naming their defaults, using the configuration
+// carried by each request since the defaults may differ per request.
+func Filter(cfg *config.APIConfig) server.PromptFilterFunc {
+	return func(ctx context.Context, prompts []mcp.Prompt) []mcp.Prompt {
+		cfg := config.FromContext(ctx, cfg)
+		defaults := map[string]string{"project": cfg.DefaultProject, "location": cfg.DefaultLocation}
+		out := make([]mcp.Prompt, 0, len(prompts))
+		for _, p := range prompts {
+			p.Arguments = slices.Clone(p.Arguments)
+			for i, arg := range p.Arguments {
+				if value := defaults[arg.Name]; value != "" {
+					arg.Description = fmt.Sprintf("%s Defaults to %s.", arg.Description, value)
+					arg.Required = false
+					p.Arguments[i] = arg
+				}
+			}
+			out = append(out, p)
+		}
+		return out
+	}
+}
+
+// arguments returns the arguments of request with the project and location
+// defaults of the calling configuration applied, checking that the given IDs
+// are present and the project is allowed.
+func arguments(ctx context.Context, cfg *config.APIConfig, request mcp.GetPromptRequest, required ...string) (map[string]string, error) {
+	cfg = config.FromContext(ctx, cfg)
+	a := map[string]string{"project": cfg.DefaultProject, "location": cfg.DefaultLocation}
+	for name, value := range request.Params.Arguments {
+		if value != "" {
+			a[name] = value
+		}
+	}
+	for _, name := range append([]string{"project"}, required...) {
+		if a[name] == "" {
+			return nil, fmt.Errorf("missing required argument %s", name)
+		}
+	}
+	if err := cfg.CheckProject(a["project"]); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// specContents fetches a spec's contents as an embedded resource.
+func specContents(ctx context.Context, c *client.Client, name, project, location, api, version, spec string) (mcp.Content, error) {
+	body, err := c.GetApiSpecContents(ctx, project, location, api, version, spec)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := resources.Contents(resources.URI(name), body)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewEmbeddedResource(contents), nil
+}
+
+// specMetadata returns spec without its input-only contents.
+func specMetadata(spec *models.ApiSpec) *models.ApiSpec {
+	meta := *spec
+	meta.Contents = ""
+	return &meta
+}
+
+// writeJSON appends v to b as a titled JSON block.
+func writeJSON(b *strings.Builder, title string, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		data = []byte(err.Error())
+	}
+	fmt.Fprintf(b, "%s:\n```json\n%s\n```\n\n", title, data)
+}
+
+func orUnset(s string) string {
+	if s == "" {
+		return "(not set)"
+	}
+	return s
+}
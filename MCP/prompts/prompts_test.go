@@ -0,0 +1,219 @@
+package prompts
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/fake"
+)
+
+// newRegistry serves a seeded fake registry and returns a configuration for
+// it.
+func newRegistry(t *testing.T) *config.APIConfig {
+	t.Helper()
+	f := fake.New()
+	f.Seed("demo", "global")
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return &config.APIConfig{
+		BaseURL:         srv.URL,
+		Timeout:         5 * time.Second,
+		Retry:           config.RetryPolicy{MaxAttempts: 1},
+		DefaultLocation: config.DefaultLocationID,
+	}
+}
+
+// render gets the prompt called name with the given arguments and returns
+// the text of its messages, including embedded resources.
+func render(ctx context.Context, cfg *config.APIConfig, name string, args map[string]string) (string, error) {
+	for _, p := range GetAll(cfg) {
+		if p.Definition.Name != name {
+			continue
+		}
+		request := mcp.GetPromptRequest{}
+		request.Params.Name = name
+		request.Params.Arguments = args
+		result, err := p.Handler(ctx, request)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		for _, m := range result.Messages {
+			switch c := m.Content.(type) {
+			case mcp.TextContent:
+				b.WriteString(c.Text)
+			case mcp.EmbeddedResource:
+				if r, ok := c.Resource.(mcp.TextResourceContents); ok {
+					b.WriteString(r.URI + "\n" + r.Text)
+				}
+			}
+			b.WriteString("\n")
+		}
+		return b.String(), nil
+	}
+	return "", nil
+}
+
+func TestPrompts(t *testing.T) {
+	cfg := newRegistry(t)
+	const spec = "projects/demo/locations/global/apis/petstore/versions/v1/specs/openapi"
+	tests := []struct {
+		name   string
+		prompt string
+		args   map[string]string
+		want   []string
+		not    string // Excluded from the rendering
+	}{
+		{
+			name:   "review",
+			prompt: "review-api-spec",
+			args:   map[string]string{"project": "demo", "api": "petstore", "version": "v1", "spec": "openapi"},
+			want:   []string{"Review the API spec " + spec + " (version state: PRODUCTION)", `"filename": "openapi.yaml"`, "registry://" + spec, "version: 1.0.1"},
+		},
+		{
+			name:   "portfolio",
+			prompt: "summarize-api-portfolio",
+			args:   map[string]string{"project": "demo"},
+			want:   []string{"projects/demo/locations/global.", "APIs (2)", `"displayName": "Payments"`, "Deployments (1)", `"endpointUri": "https://petstore.example.com/v1"`},
+		},
+		{
+			name:   "portfolio filtered",
+			prompt: "summarize-api-portfolio",
+			args:   map[string]string{"project": "demo", "filter": "labels.team == 'pets'"},
+			want:   []string{"APIs (1)", `"displayName": "Swagger Petstore"`},
+			not:    `"displayName": "Payments"`,
+		},
+		{
+			name:   "deprecation",
+			prompt: "prepare-deprecation-notice",
+			args:   map[string]string{"project": "demo", "api": "petstore", "version": "v1", "sunset": "2027-01-01"},
+			want:   []string{"projects/demo/locations/global/apis/petstore/versions/v1.", "Sunset date: 2027-01-01", "Replacement version: (not set)", "Deployments (1)"},
+		},
+		{
+			name:   "compare latest",
+			prompt: "compare-spec-revisions",
+			args:   map[string]string{"project": "demo", "api": "petstore", "version": "v1", "spec": "openapi"},
+			want:   []string{"Base: " + spec + "@00000001", "Target: " + spec + "@00000002", "version: 1.0.0", "version: 1.0.1"},
+		},
+		{
+			name:   "compare given",
+			prompt: "compare-spec-revisions",
+			args:   map[string]string{"project": "demo", "api": "petstore", "version": "v1", "spec": "openapi", "baseRevision": "00000002", "targetRevision": "00000001"},
+			want:   []string{"Compare revision 00000002 (base) with revision 00000001 (target)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := render(context.Background(), cfg, tt.prompt, tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("rendered %q, want it to contain %q", got, want)
+				}
+			}
+			if tt.not != "" && strings.Contains(got, tt.not) {
+				t.Errorf("rendered %q, want it not to contain %q", got, tt.not)
+			}
+		})
+	}
+}
+
+func TestPromptErrors(t *testing.T) {
+	cfg := newRegistry(t)
+	cfg.AllowedProjects = []string{"demo", "other"}
+	tests := []struct {
+		name   string
+		prompt string
+		args   map[string]string
+		want   string
+	}{
+		{
+			name:   "missing project",
+			prompt: "summarize-api-portfolio",
+			args:   map[string]string{},
+			want:   "missing required argument project",
+		},
+		{
+			name:   "missing ID",
+			prompt: "review-api-spec",
+			args:   map[string]string{"project": "demo", "api": "petstore", "version": "v1"},
+			want:   "missing required argument spec",
+		},
+		{
+			name:   "project not allowed",
+			prompt: "summarize-api-portfolio",
+			args:   map[string]string{"project": "elsewhere"},
+			want:   "elsewhere",
+		},
+		{
+			name:   "missing spec",
+			prompt: "review-api-spec",
+			args:   map[string]string{"project": "demo", "api": "petstore", "version": "v1", "spec": "missing"},
+			want:   "NOT_FOUND",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := render(context.Background(), cfg, tt.prompt, tt.args); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestPromptDefaults checks that the project and location defaults of the
+// request's configuration, not only the server's, are applied when prompts
+// are listed and rendered.
+func TestPromptDefaults(t *testing.T) {
+	cfg := newRegistry(t)
+	var definitions []mcp.Prompt
+	for _, p := range GetAll(cfg) {
+		definitions = append(definitions, p.Definition)
+	}
+	arg := func(prompts []mcp.Prompt, name string) mcp.PromptArgument {
+		for _, a := range prompts[0].Arguments {
+			if a.Name == name {
+				return a
+			}
+		}
+		t.Fatalf("prompt %s has no %s argument", prompts[0].Name, name)
+		return mcp.PromptArgument{}
+	}
+
+	listed := Filter(cfg)(context.Background(), definitions)
+	if p := arg(listed, "project"); !p.Required || p.Description != "Project ID." {
+		t.Errorf("without a default project listed %+v, want it required", p)
+	}
+	if l := arg(listed, "location"); l.Required || l.Description != "Location ID. Defaults to global." {
+		t.Errorf("listed location %+v, want it to name its default", l)
+	}
+
+	request := *cfg
+	request.DefaultProject = "demo"
+	ctx := config.NewContext(context.Background(), &request)
+	listed = Filter(cfg)(ctx, definitions)
+	if p := arg(listed, "project"); p.Required || p.Description != "Project ID. Defaults to demo." {
+		t.Errorf("with a default project listed %+v, want it optional", p)
+	}
+	if p := arg(listed, "api"); !p.Required {
+		t.Errorf("listed %+v, want it required", p)
+	}
+	if p := arg(definitions, "project"); !p.Required || p.Description != "Project ID." {
+		t.Errorf("listing changed the registered definition to %+v", p)
+	}
+
+	got, err := render(ctx, cfg, "summarize-api-portfolio", map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "projects/demo/locations/global.") {
+		t.Errorf("rendered %q, want the request's default project", got)
+	}
+}
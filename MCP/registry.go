@@ -1,47 +1,211 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/metrics"
 	"github.com/registry-api/mcp-server/models"
 	tools_registry "github.com/registry-api/mcp-server/tools/registry"
 )
 
+// registryTool names a generated tool under each naming scheme. The legacy
+// path-based name is the one the generated constructor assigns.
+type registryTool struct {
+	short       string
+	operationID string
+	create      func(*config.APIConfig) models.Tool
+}
+
+var registryTools = []registryTool{
+	{"list_apis", "Registry_ListApis", tools_registry.CreateRegistry_listapisTool},
+	{"get_api", "Registry_GetApi", tools_registry.CreateRegistry_getapiTool},
+	{"create_api", "Registry_CreateApi", tools_registry.CreateRegistry_createapiTool},
+	{"update_api", "Registry_UpdateApi", tools_registry.CreateRegistry_updateapiTool},
+	{"delete_api", "Registry_DeleteApi", tools_registry.CreateRegistry_deleteapiTool},
+
+	{"list_versions", "Registry_ListApiVersions", tools_registry.CreateRegistry_listapiversionsTool},
+	{"get_version", "Registry_GetApiVersion", tools_registry.CreateRegistry_getapiversionTool},
+	{"create_version", "Registry_CreateApiVersion", tools_registry.CreateRegistry_createapiversionTool},
+	{"update_version", "Registry_UpdateApiVersion", tools_registry.CreateRegistry_updateapiversionTool},
+	{"delete_version", "Registry_DeleteApiVersion", tools_registry.CreateRegistry_deleteapiversionTool},
+
+	{"list_specs", "Registry_ListApiSpecs", tools_registry.CreateRegistry_listapispecsTool},
+	{"get_spec", "Registry_GetApiSpec", tools_registry.CreateRegistry_getapispecTool},
+	{"get_spec_contents", "Registry_GetApiSpecContents", tools_registry.CreateRegistry_getapispeccontentsTool},
+	{"create_spec", "Registry_CreateApiSpec", tools_registry.CreateRegistry_createapispecTool},
+	{"update_spec", "Registry_UpdateApiSpec", tools_registry.CreateRegistry_updateapispecTool},
+	{"delete_spec", "Registry_DeleteApiSpec", tools_registry.CreateRegistry_deleteapispecTool},
+	{"list_spec_revisions", "Registry_ListApiSpecRevisions", tools_registry.CreateRegistry_listapispecrevisionsTool},
+	{"tag_spec_revision", "Registry_TagApiSpecRevision", tools_registry.CreateRegistry_tagapispecrevisionTool},
+	{"rollback_spec", "Registry_RollbackApiSpec", tools_registry.CreateRegistry_rollbackapispecTool},
+	{"delete_spec_revision", "Registry_DeleteApiSpecRevision", tools_registry.CreateRegistry_deleteapispecrevisionTool},
+
+	{"list_deployments", "Registry_ListApiDeployments", tools_registry.CreateRegistry_listapideploymentsTool},
+	{"get_deployment", "Registry_GetApiDeployment", tools_registry.CreateRegistry_getapideploymentTool},
+	{"create_deployment", "Registry_CreateApiDeployment", tools_registry.CreateRegistry_createapideploymentTool},
+	{"update_deployment", "Registry_UpdateApiDeployment", tools_registry.CreateRegistry_updateapideploymentTool},
+	{"delete_deployment", "Registry_DeleteApiDeployment", tools_registry.CreateRegistry_deleteapideploymentTool},
+	{"list_deployment_revisions", "Registry_ListApiDeploymentRevisions", tools_registry.CreateRegistry_listapideploymentrevisionsTool},
+	{"tag_deployment_revision", "Registry_TagApiDeploymentRevision", tools_registry.CreateRegistry_tagapideploymentrevisionTool},
+	{"rollback_deployment", "Registry_RollbackApiDeployment", tools_registry.CreateRegistry_rollbackapideploymentTool},
+	{"delete_deployment_revision", "Registry_DeleteApiDeploymentRevision", tools_registry.CreateRegistry_deleteapideploymentrevisionTool},
+
+	{"list_artifacts", "Registry_ListArtifacts", tools_registry.CreateRegistry_listartifactsTool},
+	{"get_artifact", "Registry_GetArtifact", tools_registry.CreateRegistry_getartifactTool},
+	{"get_artifact_contents", "Registry_GetArtifactContents", tools_registry.CreateRegistry_getartifactcontentsTool},
+	{"create_artifact", "Registry_CreateArtifact", tools_registry.CreateRegistry_createartifactTool},
+	{"replace_artifact", "Registry_ReplaceArtifact", tools_registry.CreateRegistry_replaceartifactTool},
+	{"delete_artifact", "Registry_DeleteArtifact", tools_registry.CreateRegistry_deleteartifactTool},
+}
+
+// GetAll returns the registry tools selected by cfg.ToolFilters and named
+// according to cfg.ToolNaming. Each handler is wrapped as
+//
+//	withTracing(withMetrics(withName(withScope(withAudit(withConfirm(withDryRun(withArgumentChecks(handler))))))))
+//
+// so, from the outside in, every call is traced and counted, full resource
+// names and the default project and location are resolved, and only then is
+// a mutating call audited, confirmed and possibly just planned.
 func GetAll(cfg *config.APIConfig) []models.Tool {
-	return []models.Tool{
-		tools_registry.CreateRegistry_deleteapispecTool(cfg),
-		tools_registry.CreateRegistry_getapispecTool(cfg),
-		tools_registry.CreateRegistry_updateapispecTool(cfg),
-		tools_registry.CreateRegistry_getartifactcontentsTool(cfg),
-		tools_registry.CreateRegistry_deleteapiversionTool(cfg),
-		tools_registry.CreateRegistry_getapiversionTool(cfg),
-		tools_registry.CreateRegistry_updateapiversionTool(cfg),
-		tools_registry.CreateRegistry_createapispecTool(cfg),
-		tools_registry.CreateRegistry_listapispecsTool(cfg),
-		tools_registry.CreateRegistry_rollbackapispecTool(cfg),
-		tools_registry.CreateRegistry_listartifactsTool(cfg),
-		tools_registry.CreateRegistry_createartifactTool(cfg),
-		tools_registry.CreateRegistry_deleteapispecrevisionTool(cfg),
-		tools_registry.CreateRegistry_deleteapideploymentTool(cfg),
-		tools_registry.CreateRegistry_getapideploymentTool(cfg),
-		tools_registry.CreateRegistry_updateapideploymentTool(cfg),
-		tools_registry.CreateRegistry_listapiversionsTool(cfg),
-		tools_registry.CreateRegistry_createapiversionTool(cfg),
-		tools_registry.CreateRegistry_deleteapiTool(cfg),
-		tools_registry.CreateRegistry_getapiTool(cfg),
-		tools_registry.CreateRegistry_updateapiTool(cfg),
-		tools_registry.CreateRegistry_listapideploymentrevisionsTool(cfg),
-		tools_registry.CreateRegistry_tagapideploymentrevisionTool(cfg),
-		tools_registry.CreateRegistry_listapispecrevisionsTool(cfg),
-		tools_registry.CreateRegistry_deleteartifactTool(cfg),
-		tools_registry.CreateRegistry_getartifactTool(cfg),
-		tools_registry.CreateRegistry_replaceartifactTool(cfg),
-		tools_registry.CreateRegistry_deleteapideploymentrevisionTool(cfg),
-		tools_registry.CreateRegistry_rollbackapideploymentTool(cfg),
-		tools_registry.CreateRegistry_listapisTool(cfg),
-		tools_registry.CreateRegistry_createapiTool(cfg),
-		tools_registry.CreateRegistry_tagapispecrevisionTool(cfg),
-		tools_registry.CreateRegistry_getapispeccontentsTool(cfg),
-		tools_registry.CreateRegistry_listapideploymentsTool(cfg),
-		tools_registry.CreateRegistry_createapideploymentTool(cfg),
+	tools := make([]models.Tool, 0, len(registryTools))
+	for _, rt := range registryTools {
+		tool := rt.create(cfg)
+		if !rt.enabled(tool, cfg.ToolFilters) {
+			continue
+		}
+		tool.Definition.Name = rt.name(tool, cfg.ToolNaming)
+		tool.Definition.Annotations = rt.annotations()
+		tool = withDryRun(cfg, rt, withArgumentChecks(tool))
+		tool = withConfirm(cfg, rt, tool)
+		tool = withAudit(cfg, rt, tool)
+		tools = append(tools, withTracing(withMetrics(withName(withScope(cfg, tool)))))
+	}
+	return tools
+}
+
+func (rt registryTool) name(tool models.Tool, naming string) string {
+	switch naming {
+	case config.ToolNamingOperationID:
+		return rt.operationID
+	case config.ToolNamingLegacy:
+		return tool.Definition.Name
+	}
+	return rt.short
+}
+
+// enabled reports whether every filter allows the tool.
+func (rt registryTool) enabled(tool models.Tool, filters []config.ToolFilter) bool {
+	names := []string{rt.short, rt.operationID, tool.Definition.Name}
+	verb, rest, _ := strings.Cut(rt.short, "_")
+	resource, _, _ := strings.Cut(rest, "_")
+	resource = strings.TrimSuffix(resource, "s") // list_apis -> api
+	for _, f := range filters {
+		if !f.Allows(names, verb, resource, rt.readOnly()) {
+			return false
+		}
+	}
+	return true
+}
+
+// readOnly reports whether the tool leaves the registry unchanged.
+func (rt registryTool) readOnly() bool {
+	return strings.HasPrefix(rt.short, "get_") || strings.HasPrefix(rt.short, "list_")
+}
+
+// annotations describes the tool's behaviour to clients.
+func (rt registryTool) annotations() mcp.ToolAnnotation {
+	verb, _, _ := strings.Cut(rt.short, "_")
+	readOnly := rt.readOnly()
+	// Creating and tagging only add to the registry; everything else that
+	// writes can overwrite or remove data.
+	destructive := !readOnly && verb != "create" && verb != "tag"
+	// Creating fails the second time and every rollback adds a revision.
+	idempotent := verb != "create" && verb != "rollback"
+	return mcp.ToolAnnotation{
+		ReadOnlyHint:    mcp.ToBoolPtr(readOnly),
+		DestructiveHint: mcp.ToBoolPtr(destructive),
+		IdempotentHint:  mcp.ToBoolPtr(idempotent),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	}
+}
+
+// needsConfirmation reports whether the tool deletes data or rolls it back,
+// and so must be confirmed before it runs.
+func (rt registryTool) needsConfirmation() bool {
+	return strings.HasPrefix(rt.short, "delete_") || strings.HasPrefix(rt.short, "rollback_")
+}
+
+// requestTools returns a tools/list filter and a tools/call middleware
+// applying the configuration carried by each request, which may narrow the
+// tool selection and set other defaults than cfg.
+func requestTools(cfg *config.APIConfig) (server.ToolFilterFunc, server.ToolHandlerMiddleware) {
+	type generated struct {
+		rt   registryTool
+		tool models.Tool
+	}
+	byName := map[string]generated{}
+	for _, rt := range registryTools {
+		tool := rt.create(cfg)
+		byName[rt.name(tool, cfg.ToolNaming)] = generated{rt, tool}
+	}
+	allowed := func(cfg *config.APIConfig, name string) bool {
+		g, ok := byName[name]
+		return !ok || g.rt.enabled(g.tool, cfg.ToolFilters)
+	}
+
+	filter := func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		cfg := config.FromContext(ctx, cfg)
+		out := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if allowed(cfg, tool.Name) {
+				out = append(out, describeDefaults(cfg, tool))
+			}
+		}
+		return out
+	}
+	middleware := func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !allowed(config.FromContext(ctx, cfg), request.Params.Name) {
+				// Refused before withMetrics sees the call, so it is counted here.
+				metrics.ToolCalls.WithLabelValues(request.Params.Name).Inc()
+				metrics.ToolErrors.WithLabelValues(request.Params.Name, "PERMISSION_DENIED").Inc()
+				return mcp.NewToolResultError(fmt.Sprintf("tool %s is not enabled for this connection", request.Params.Name)), nil
+			}
+			return next(ctx, request)
+		}
+	}
+	return filter, middleware
+}
+
+// toolAliases maps the names of every tool under the other naming schemes to
+// the name it is registered under, so clients configured for another scheme
+// keep working.
+func toolAliases(cfg *config.APIConfig) map[string]string {
+	aliases := map[string]string{}
+	for _, rt := range registryTools {
+		tool := rt.create(cfg)
+		registered := rt.name(tool, cfg.ToolNaming)
+		for _, naming := range []string{config.ToolNamingShort, config.ToolNamingOperationID, config.ToolNamingLegacy} {
+			if alias := rt.name(tool, naming); alias != registered {
+				aliases[alias] = registered
+			}
+		}
+	}
+	return aliases
+}
+
+// aliasHook rewrites calls made under an alias to the registered tool name.
+// Aliases are accepted by tools/call but not advertised by tools/list.
+func aliasHook(cfg *config.APIConfig) server.OnBeforeCallToolFunc {
+	aliases := toolAliases(cfg)
+	return func(ctx context.Context, id any, request *mcp.CallToolRequest) {
+		if name, ok := aliases[request.Params.Name]; ok {
+			request.Params.Name = name
+		}
 	}
 }
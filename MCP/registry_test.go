@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/config"
+)
+
+// TestToolNaming checks that tools are listed under the configured naming
+// scheme and can still be called by their names under the other schemes.
+func TestToolNaming(t *testing.T) {
+	up := &upstream{}
+	up.reset()
+	registry := httptest.NewServer(up)
+	defer registry.Close()
+
+	getAPI := map[string]string{
+		config.ToolNamingShort:       "get_api",
+		config.ToolNamingOperationID: "Registry_GetApi",
+		config.ToolNamingLegacy:      "get_v1_projects_project_locations_location_apis_api",
+	}
+	for naming, registered := range getAPI {
+		t.Run(naming, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.BaseURL = registry.URL
+			cfg.ToolNaming = naming
+			c := serveStdio(t, cfg)
+
+			res, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			listed := map[string]bool{}
+			for _, tool := range res.Tools {
+				listed[tool.Name] = true
+			}
+			if len(listed) != len(registryTools) {
+				t.Errorf("tools/list returned %d tools, want %d", len(listed), len(registryTools))
+			}
+			for _, name := range getAPI {
+				if listed[name] != (name == registered) {
+					t.Errorf("tools/list lists %s: %v, want %v", name, listed[name], name == registered)
+				}
+			}
+
+			for _, name := range getAPI {
+				res := callTool(t, c, name, demo(map[string]any{"api": "petstore"}), nil)
+				if res.IsError {
+					t.Errorf("calling %s failed: %s", name, text(res))
+					continue
+				}
+				if got := object(t, res)["displayName"]; got != "Swagger Petstore" {
+					t.Errorf("calling %s returned displayName %v", name, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package resources exposes registry APIs, spec contents and artifacts as MCP
+// resources addressed by registry:// URIs.
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+// Scheme prefixes a registry resource name to form its MCP resource URI.
+const Scheme = "registry://"
+
+// maxListed caps the number of resources returned by resources/list.
+const maxListed = 500
+
+// Template pairs a resource template with the handler reading it.
+type Template struct {
+	Definition mcp.ResourceTemplate
+	Handler    server.ResourceTemplateHandlerFunc
+}
+
+// URI returns the resource URI for a registry resource name.
+func URI(name string) string {
+	return Scheme + name
+}
+
+// GetTemplates returns the registry resource templates.
+func GetTemplates(cfg *config.APIConfig) []Template {
+	c := client.New(cfg)
+	return []Template{
+		{
+			Definition: mcp.NewResourceTemplate(
+				Scheme+"projects/{project}/locations/{location}/apis/{api}",
+				"API",
+				mcp.WithTemplateDescription("Metadata of a registry API, including its recommended version and deployment."),
+				mcp.WithTemplateMIMEType("application/json"),
+			),
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				args := request.Params.Arguments
+				if err := config.FromContext(ctx, cfg).CheckProject(arg(args, "project")); err != nil {
+					return nil, err
+				}
+				api, err := c.GetApi(ctx, arg(args, "project"), arg(args, "location"), arg(args, "api"))
+				if err != nil {
+					return nil, err
+				}
+				return jsonContents(request.Params.URI, api)
+			},
+		},
+		{
+			Definition: mcp.NewResourceTemplate(
+				Scheme+"projects/{project}/locations/{location}/apis/{api}/versions/{version}/specs/{spec}",
+				"API spec contents",
+				mcp.WithTemplateDescription("The contents of an API spec, e.g. an OpenAPI document. Gzipped specs are decompressed; binary specs are returned as blobs."),
+			),
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				args := request.Params.Arguments
+				if err := config.FromContext(ctx, cfg).CheckProject(arg(args, "project")); err != nil {
+					return nil, err
+				}
+				body, err := c.GetApiSpecContents(ctx, arg(args, "project"), arg(args, "location"), arg(args, "api"), arg(args, "version"), arg(args, "spec"))
+				if err != nil {
+					return nil, err
+				}
+				return bodyContents(request.Params.URI, body)
+			},
+		},
+		{
+			Definition: mcp.NewResourceTemplate(
+				Scheme+"projects/{project}/locations/{location}/apis/{api}/deployments/{deployment}",
+				"API deployment",
+				mcp.WithTemplateDescription("Metadata of an API deployment, including its current revision."),
+				mcp.WithTemplateMIMEType("application/json"),
+			),
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				args := request.Params.Arguments
+				if err := config.FromContext(ctx, cfg).CheckProject(arg(args, "project")); err != nil {
+					return nil, err
+				}
+				deployment, err := c.GetApiDeployment(ctx, arg(args, "project"), arg(args, "location"), arg(args, "api"), arg(args, "deployment"))
+				if err != nil {
+					return nil, err
+				}
+				return jsonContents(request.Params.URI, deployment)
+			},
+		},
+		{
+			Definition: mcp.NewResourceTemplate(
+				Scheme+"projects/{project}/locations/{location}/artifacts/{artifact}",
+				"Artifact contents",
+				mcp.WithTemplateDescription("The contents of a registry artifact."),
+			),
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				args := request.Params.Arguments
+				if err := config.FromContext(ctx, cfg).CheckProject(arg(args, "project")); err != nil {
+					return nil, err
+				}
+				body, err := c.GetArtifactContents(ctx, arg(args, "project"), arg(args, "location"), arg(args, "artifact"))
+				if err != nil {
+					return nil, err
+				}
+				return bodyContents(request.Params.URI, body)
+			},
+		},
+	}
+}
+
+// ListHook returns a resources/list hook that appends the APIs and specs
+// found under the default project and location of the calling configuration.
+// mcp-go only lists statically registered resources, so the registry listing
+// is merged into its result. Without a default project nothing is added; the
+// templates still allow reading any resource.
+func ListHook(cfg *config.APIConfig) server.OnAfterListResourcesFunc {
+	c := client.New(cfg)
+	return func(ctx context.Context, id any, request *mcp.ListResourcesRequest, result *mcp.ListResourcesResult) {
+		cfg := config.FromContext(ctx, cfg)
+		if cfg.DefaultProject == "" || request.Params.Cursor != "" {
+			return
+		}
+		listed, err := list(ctx, c, cfg.DefaultProject, cfg.DefaultLocation)
+		if err != nil {
+			log.Printf("Listing registry resources failed: %v", err)
+		}
+		result.Resources = append(result.Resources, listed...)
+	}
+}
+
+func list(ctx context.Context, c *client.Client, project, location string) ([]mcp.Resource, error) {
+	apis, _, err := client.CollectPages(ctx, nil, maxListed, func(ctx context.Context, opts *client.ListOptions) ([]models.Api, string, error) {
+		page, err := c.ListApis(ctx, project, location, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Apis, page.Nextpagetoken, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []mcp.Resource
+	for _, api := range apis {
+		title := api.Displayname
+		if title == "" {
+			title = api.Name
+		}
+		out = append(out, mcp.NewResource(URI(api.Name), title,
+			mcp.WithResourceDescription(api.Description),
+			mcp.WithMIMEType("application/json"),
+		))
+	}
+
+	// The specs of all versions of all APIs.
+	specs, _, err := client.CollectPages(ctx, nil, maxListed, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiSpec, string, error) {
+		page, err := c.ListApiSpecs(ctx, project, location, names.Wildcard, names.Wildcard, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Apispecs, page.Nextpagetoken, nil
+	})
+	if err != nil {
+		return out, err
+	}
+	for _, spec := range specs {
+		title := spec.Filename
+		if title == "" {
+			title = spec.Name
+		}
+		out = append(out, mcp.NewResource(URI(spec.Name), title,
+			mcp.WithResourceDescription(spec.Description),
+			mcp.WithMIMEType(strings.Replace(spec.Mimetype, "+gzip", "", 1)),
+		))
+	}
+	return out, nil
+}
+
+// Contents converts a getContents payload into resource contents: text for
+// textual formats, a base64 blob otherwise.
+func Contents(uri string, body *client.HttpBody) (mcp.ResourceContents, error) {
+	data, mimeType, err := body.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	if client.IsText(mimeType, data) {
+		return mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(data)}, nil
+	}
+	return mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(data)}, nil
+}
+
+func bodyContents(uri string, body *client.HttpBody) ([]mcp.ResourceContents, error) {
+	contents, err := Contents(uri, body)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{contents}, nil
+}
+
+func jsonContents(uri string, v any) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", uri, err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)}}, nil
+}
+
+func arg(args map[string]any, name string) string {
+	switch v := args[name].(type) {
+	case string:
+		return v
+	case []string:
+		// URI template matches yield a list holding the single value.
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+package resources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/fake"
+)
+
+const demoVersion = "projects/demo/locations/global/apis/petstore/versions/v1"
+
+// newRegistry serves a seeded fake registry and returns a configuration for
+// it.
+func newRegistry(t *testing.T) (*config.APIConfig, *fake.Registry) {
+	t.Helper()
+	f := fake.New()
+	f.Seed("demo", "global")
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return &config.APIConfig{
+		BaseURL:         srv.URL,
+		Timeout:         5 * time.Second,
+		Retry:           config.RetryPolicy{MaxAttempts: 1},
+		DefaultLocation: config.DefaultLocationID,
+	}, f
+}
+
+// createSpec adds a spec with the given media type and contents to the demo
+// version.
+func createSpec(t *testing.T, cfg *config.APIConfig, id, mimeType string, contents []byte) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"mimeType": mimeType, "contents": base64.StdEncoding.EncodeToString(contents)})
+	resp, err := http.Post(cfg.BaseURL+"/v1/"+demoVersion+"/specs?apiSpecId="+id, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("creating spec %s: %s", id, resp.Status)
+	}
+}
+
+// read reads uri through the template at index i of GetTemplates, with the
+// URI's variables as arguments.
+func read(t *testing.T, cfg *config.APIConfig, i int, uri string, args map[string]any) mcp.ResourceContents {
+	t.Helper()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	request.Params.Arguments = args
+	contents, err := GetTemplates(cfg)[i].Handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("reading %s: %v", uri, err)
+	}
+	if len(contents) != 1 {
+		t.Fatalf("reading %s returned %d contents, want 1", uri, len(contents))
+	}
+	return contents[0]
+}
+
+func TestTemplates(t *testing.T) {
+	cfg, _ := newRegistry(t)
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	zw.Write([]byte("openapi: 3.1.0\n"))
+	zw.Close()
+	createSpec(t, cfg, "gzipped", "application/x.openapi+gzip;version=3", gzipped.Bytes())
+	archive := []byte("PK\x03\x04\x00\x00\xff\xfe binary")
+	createSpec(t, cfg, "archive", "application/zip", archive)
+
+	spec := func(id string) map[string]any {
+		return map[string]any{"project": []string{"demo"}, "location": []string{"global"}, "api": []string{"petstore"}, "version": []string{"v1"}, "spec": []string{id}}
+	}
+	tests := []struct {
+		name     string
+		template int
+		uri      string
+		args     map[string]any
+		mimeType string
+		text     string // Substring of text contents
+		blob     []byte // Decoded blob contents
+	}{
+		{
+			name:     "API",
+			template: 0,
+			uri:      URI("projects/demo/locations/global/apis/petstore"),
+			args:     map[string]any{"project": "demo", "location": "global", "api": "petstore"},
+			mimeType: "application/json",
+			text:     `"displayName": "Swagger Petstore"`,
+		},
+		{
+			name:     "text spec",
+			template: 1,
+			uri:      URI(demoVersion + "/specs/openapi"),
+			args:     spec("openapi"),
+			mimeType: "application/x.openapi+yaml;version=3",
+			text:     "title: Swagger Petstore",
+		},
+		{
+			name:     "gzipped spec",
+			template: 1,
+			uri:      URI(demoVersion + "/specs/gzipped"),
+			args:     spec("gzipped"),
+			mimeType: "application/x.openapi;version=3",
+			text:     "openapi: 3.1.0",
+		},
+		{
+			name:     "binary spec",
+			template: 1,
+			uri:      URI(demoVersion + "/specs/archive"),
+			args:     spec("archive"),
+			mimeType: "application/zip",
+			blob:     archive,
+		},
+		{
+			name:     "deployment",
+			template: 2,
+			uri:      URI("projects/demo/locations/global/apis/petstore/deployments/prod"),
+			args:     map[string]any{"project": "demo", "location": "global", "api": "petstore", "deployment": "prod"},
+			mimeType: "application/json",
+			text:     `"endpointUri": "https://petstore.example.com/v1"`,
+		},
+		{
+			name:     "artifact",
+			template: 3,
+			uri:      URI("projects/demo/locations/global/artifacts/style-guide"),
+			args:     map[string]any{"project": "demo", "location": "global", "artifact": "style-guide"},
+			mimeType: "text/markdown",
+			text:     "# API style guide",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			switch c := read(t, cfg, tt.template, tt.uri, tt.args).(type) {
+			case mcp.TextResourceContents:
+				if tt.blob != nil || c.URI != tt.uri || c.MIMEType != tt.mimeType || !strings.Contains(c.Text, tt.text) {
+					t.Errorf("read text %s (%s): %.80q, want %s (%s) containing %q", c.URI, c.MIMEType, c.Text, tt.uri, tt.mimeType, tt.text)
+				}
+			case mcp.BlobResourceContents:
+				data, err := base64.StdEncoding.DecodeString(c.Blob)
+				if err != nil || tt.blob == nil || c.URI != tt.uri || c.MIMEType != tt.mimeType || !bytes.Equal(data, tt.blob) {
+					t.Errorf("read blob %s (%s): %q, want %s (%s) %q", c.URI, c.MIMEType, data, tt.uri, tt.mimeType, tt.blob)
+				}
+			default:
+				t.Errorf("read %T", c)
+			}
+		})
+	}
+}
+
+func TestTemplatesDisallowedProject(t *testing.T) {
+	cfg, f := newRegistry(t)
+	cfg.AllowedProjects = []string{"other"}
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = URI("projects/demo/locations/global/apis/petstore")
+	request.Params.Arguments = map[string]any{"project": "demo", "location": "global", "api": "petstore"}
+	if _, err := GetTemplates(cfg)[0].Handler(context.Background(), request); err == nil {
+		t.Error("read of a disallowed project succeeded")
+	}
+	if n := len(f.Requests()); n != 0 {
+		t.Errorf("sent %d requests for a disallowed project, want none", n)
+	}
+}
+
+func TestListHook(t *testing.T) {
+	cfg, _ := newRegistry(t)
+	listResources := func(cfg *config.APIConfig, cursor mcp.Cursor) []string {
+		t.Helper()
+		request := &mcp.ListResourcesRequest{}
+		request.Params.Cursor = cursor
+		result := &mcp.ListResourcesResult{Resources: []mcp.Resource{mcp.NewResource("static://readme", "README")}}
+		ListHook(cfg)(context.Background(), 1, request, result)
+		var uris []string
+		for _, r := range result.Resources {
+			uris = append(uris, r.URI)
+		}
+		return uris
+	}
+
+	if got := listResources(cfg, ""); len(got) != 1 {
+		t.Errorf("listed %v without a default project, want only the static resource", got)
+	}
+
+	cfg.DefaultProject = "demo"
+	want := []string{
+		"static://readme",
+		URI("projects/demo/locations/global/apis/payments"),
+		URI("projects/demo/locations/global/apis/petstore"),
+		URI(demoVersion + "/specs/openapi"),
+	}
+	if got := listResources(cfg, ""); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("listed %v, want %v", got, want)
+	}
+	if got := listResources(cfg, "page-2"); len(got) != 1 {
+		t.Errorf("listed %v for a later page, want only the static resource", got)
+	}
+}
@@ -0,0 +1,243 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/names"
+)
+
+// Watcher implements resources/subscribe by polling the registry. Subscribed
+// specs and deployments are fetched every interval and subscribers receive
+// notifications/resources/updated when the revision changes, which covers new
+// spec revisions, deployment rollbacks and edits of the current revision.
+//
+// Each subscription is polled with the configuration, and so the credentials,
+// of the request that made it. The polling goroutine only runs while at least
+// one subscription exists.
+type Watcher struct {
+	cfg      *config.APIConfig
+	client   *client.Client
+	interval time.Duration
+
+	mu      sync.Mutex
+	srv     *server.MCPServer
+	subs    map[string]map[string]*subscription // URI -> session ID -> subscription
+	running bool
+}
+
+type subscription struct {
+	cfg      *config.APIConfig
+	revision string // Last observed revision
+	known    bool   // Whether revision has been observed
+}
+
+// NewWatcher returns a Watcher polling every cfg.WatchInterval, with cfg's
+// credentials unless a subscribing request carries its own.
+func NewWatcher(cfg *config.APIConfig) *Watcher {
+	return &Watcher{
+		cfg:      cfg,
+		client:   client.New(cfg, client.WithCache(nil)), // Polls must see changes
+		interval: cfg.WatchInterval,
+		subs:     map[string]map[string]*subscription{},
+	}
+}
+
+// Register adds the subscription tracking hooks to hooks. Subscriptions to
+// resources that cannot be watched, or that lie in a project outside
+// ALLOWED_PROJECTS, are rejected with an error.
+func (w *Watcher) Register(hooks *server.Hooks) {
+	// mcp-go's subscribe hooks cannot fail the request, so the check runs
+	// on the raw request before it is handled.
+	hooks.AddOnRequestInitialization(func(ctx context.Context, id any, message any) error {
+		raw, ok := message.(json.RawMessage)
+		if !ok {
+			return nil
+		}
+		var request struct {
+			Method string `json:"method"`
+			Params struct {
+				URI string `json:"uri"`
+			} `json:"params"`
+		}
+		if json.Unmarshal(raw, &request) != nil || request.Method != string(mcp.MethodResourcesSubscribe) {
+			return nil
+		}
+		_, err := subscribable(config.FromContext(ctx, w.cfg), request.Params.URI)
+		return err
+	})
+	hooks.AddAfterSubscribe(func(ctx context.Context, id any, request *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		w.subscribe(ctx, request.Params.URI)
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, request *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		w.unsubscribe(sessionID(ctx), request.Params.URI)
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		w.unsubscribe(session.SessionID(), "")
+	})
+}
+
+func (w *Watcher) subscribe(ctx context.Context, uri string) {
+	session := sessionID(ctx)
+	if session == "" {
+		return
+	}
+	cfg := config.FromContext(ctx, w.cfg)
+	if _, err := subscribable(cfg, uri); err != nil {
+		// Already rejected before the request was handled.
+		return
+	}
+
+	// Record the current revision so the first poll does not report a change.
+	revision, err := w.revision(ctx, uri)
+	if err != nil {
+		log.Printf("Reading %s for subscription failed: %v", uri, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if srv := server.ServerFromContext(ctx); srv != nil {
+		w.srv = srv
+	}
+	if w.subs[uri] == nil {
+		w.subs[uri] = map[string]*subscription{}
+	}
+	w.subs[uri][session] = &subscription{cfg: cfg, revision: revision, known: err == nil}
+	if !w.running {
+		w.running = true
+		go w.run()
+	}
+}
+
+// unsubscribe removes session's subscription to uri, or all of its
+// subscriptions when uri is empty.
+func (w *Watcher) unsubscribe(session, uri string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for u, sessions := range w.subs {
+		if uri != "" && u != uri {
+			continue
+		}
+		delete(sessions, session)
+		if len(sessions) == 0 {
+			delete(w.subs, u)
+		}
+	}
+}
+
+func (w *Watcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		w.mu.Lock()
+		if len(w.subs) == 0 {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		type target struct{ uri, session string }
+		var targets []target
+		for uri, sessions := range w.subs {
+			for session := range sessions {
+				targets = append(targets, target{uri, session})
+			}
+		}
+		w.mu.Unlock()
+
+		for _, t := range targets {
+			w.poll(t.uri, t.session)
+		}
+	}
+}
+
+func (w *Watcher) poll(uri, session string) {
+	w.mu.Lock()
+	sub := w.subs[uri][session]
+	w.mu.Unlock()
+	if sub == nil {
+		// Unsubscribed since the poll started.
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(config.NewContext(context.Background(), sub.cfg), w.interval)
+	defer cancel()
+	revision, err := w.revision(ctx, uri)
+	if err != nil {
+		log.Printf("Polling %s for session %s failed: %v", uri, session, err)
+		return
+	}
+
+	w.mu.Lock()
+	// The session may have unsubscribed while the resource was read.
+	changed := w.subs[uri][session] == sub && sub.known && sub.revision != revision
+	sub.revision, sub.known = revision, true
+	srv := w.srv
+	w.mu.Unlock()
+
+	if changed {
+		err := srv.SendNotificationToSpecificClient(session, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+		if err != nil {
+			log.Printf("Notifying session %s of %s failed: %v", session, uri, err)
+		}
+	}
+}
+
+// revision returns a value that changes whenever the resource behind uri does.
+func (w *Watcher) revision(ctx context.Context, uri string) (string, error) {
+	name, err := watched(uri)
+	if err != nil {
+		return "", err
+	}
+	project, location, api := name.ID(names.Projects), name.ID(names.Locations), name.ID(names.Apis)
+	if name.Collection() == names.Deployments {
+		d, err := w.client.GetApiDeployment(ctx, project, location, api, name.ID(names.Deployments))
+		if err != nil {
+			return "", err
+		}
+		return d.Revisionid + "@" + d.Revisionupdatetime, nil
+	}
+	s, err := w.client.GetApiSpec(ctx, project, location, api, name.ID(names.Versions), name.ID(names.Specs))
+	if err != nil {
+		return "", err
+	}
+	return s.Revisionid + "@" + s.Revisionupdatetime, nil
+}
+
+// subscribable parses the URI of a resource to subscribe to, checking that it
+// can be watched and that its project is allowed.
+func subscribable(cfg *config.APIConfig, uri string) (names.Name, error) {
+	name, err := watched(uri)
+	if err != nil {
+		return name, err
+	}
+	return name, cfg.CheckProject(name.ID(names.Projects))
+}
+
+// watched parses the URI of a spec or deployment resource. Other resources
+// carry no revision and cannot be subscribed to.
+func watched(uri string) (names.Name, error) {
+	name, err := names.Parse(strings.TrimPrefix(uri, Scheme))
+	if err != nil {
+		return name, err
+	}
+	if c := name.Collection(); !strings.HasPrefix(uri, Scheme) || (c != names.Specs && c != names.Deployments) {
+		return name, fmt.Errorf("only API spec and deployment resources can be subscribed to")
+	}
+	return name, nil
+}
+
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
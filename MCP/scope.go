@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/config"
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/names"
+)
+
+// withScope fills in the default project and location of the calling
+// configuration when a call names none, and rejects calls targeting a project
+// outside its AllowedProjects. See describeDefaults for the schema.
+func withScope(cfg *config.APIConfig, tool models.Tool) models.Tool {
+	properties := tool.Definition.InputSchema.Properties
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]any)
+		if !ok {
+			return handler(ctx, request)
+		}
+		cfg := config.FromContext(ctx, cfg)
+		args = maps.Clone(args)
+		for name, def := range scopeDefaults(cfg) {
+			if _, ok := properties[name]; !ok || def == "" {
+				continue
+			}
+			if v, _ := args[name].(string); v == "" {
+				args[name] = def
+			}
+		}
+		if project, ok := args["project"].(string); ok {
+			if err := cfg.CheckProject(project); err != nil {
+				return rejected(ctx, "PERMISSION_DENIED", err.Error()), nil
+			}
+		}
+		request.Params.Arguments = args
+		return handler(ctx, request)
+	}
+	return tool
+}
+
+func scopeDefaults(cfg *config.APIConfig) map[string]string {
+	return map[string]string{"project": cfg.DefaultProject, "location": cfg.DefaultLocation}
+}
+
+// describeDefaults returns def with its project and location arguments made
+// optional and their descriptions naming cfg's defaults, if it has any. It is
+// applied when tools are listed, since the defaults may differ per request.
+func describeDefaults(cfg *config.APIConfig, def mcp.Tool) mcp.Tool {
+	schema := &def.InputSchema
+	cloned := false
+	for name, value := range scopeDefaults(cfg) {
+		prop, ok := schema.Properties[name].(map[string]any)
+		if !ok || value == "" {
+			continue
+		}
+		if !cloned {
+			schema.Properties = maps.Clone(schema.Properties)
+			cloned = true
+		}
+		prop = maps.Clone(prop)
+		prop["description"] = fmt.Sprintf("%s Defaults to %s.", prop["description"], value)
+		schema.Properties[name] = prop
+		schema.Required = slices.DeleteFunc(slices.Clone(schema.Required), func(r string) bool { return r == name })
+	}
+	return def
+}
+
+// pathArgs maps each collection to the tool argument holding its ID.
+var pathArgs = []struct{ collection, arg string }{
+	{names.Projects, "project"},
+	{names.Locations, "location"},
+	{names.Apis, "api"},
+	{names.Versions, "version"},
+	{names.Specs, "spec"},
+	{names.Deployments, "deployment"},
+	{names.Artifacts, "artifact"},
+}
+
+// argName returns the resource name formed by the path arguments in args.
+func argName(args map[string]any) names.Name {
+	var name names.Name
+	for _, p := range pathArgs {
+		if id, _ := args[p.arg].(string); id != "" {
+			name.Segments = append(name.Segments, names.Segment{Collection: p.collection, ID: id})
+		}
+	}
+	return name
+}
+
+// targetName returns the name of the resource a call of rt with args acts
+// on. For create tools this is the new resource; named is false if its ID is
+// not given, and target is then the parent it would be created in.
+func targetName(rt registryTool, args map[string]any) (target names.Name, named bool) {
+	target = argName(args)
+	if !strings.HasPrefix(rt.short, "create_") {
+		return target, true
+	}
+	for collection, arg := range idArgs {
+		if id, _ := args[arg].(string); id != "" && slices.Contains(names.Children(target.Collection()), collection) {
+			target.Segments = append(target.Segments, names.Segment{Collection: collection, ID: id})
+			return target, true
+		}
+	}
+	return target, false
+}
+
+// targetString formats the target of a call of rt with args: the name of the
+// resource, or for a create call without an ID the collection the registry
+// adds the new resource to, e.g. projects/p/locations/global/apis.
+func targetString(rt registryTool, args map[string]any) string {
+	target, named := targetName(rt, args)
+	if named {
+		return target.String()
+	}
+	return target.String() + "/" + strings.TrimPrefix(rt.short, "create_") + "s"
+}
+
+// idArgs maps each collection to the argument naming a resource to create.
+var idArgs = map[string]string{
+	names.Apis:        "apiId",
+	names.Versions:    "apiVersionId",
+	names.Specs:       "apiSpecId",
+	names.Deployments: "apiDeploymentId",
+	names.Artifacts:   "artifactId",
+}
+
+// withName lets tool be called with a full resource name instead of the
+// split path arguments. The name is decomposed into the path; a revision or
+// tag suffix stays on the last ID, e.g. spec "s@c7cfa2a8". Create tools also
+// accept the name of the resource to create, which supplies its ID.
+func withName(tool models.Tool) models.Tool {
+	schema := &tool.Definition.InputSchema
+	var args []string
+	pattern := map[string]string{}
+	for _, p := range pathArgs {
+		if _, ok := schema.Properties[p.arg]; ok {
+			args = append(args, p.arg)
+			pattern[p.arg] = p.collection
+		}
+	}
+	if len(args) == 0 {
+		return tool
+	}
+	leaf := pattern[args[len(args)-1]]
+	idArg := ""
+	for collection, arg := range idArgs {
+		if _, ok := schema.Properties[arg]; ok && slices.Contains(names.Children(leaf), collection) {
+			idArg = arg
+		}
+	}
+
+	var example []string
+	for _, arg := range args {
+		example = append(example, fmt.Sprintf("%s/{%s}", pattern[arg], arg))
+	}
+	description := fmt.Sprintf("Full resource name %s, as returned by the registry. Can be given instead of the %s arguments.", strings.Join(example, "/"), strings.Join(args, ", "))
+	if leaf == names.Specs || leaf == names.Deployments {
+		description += " A @revision or @tag suffix is kept."
+	}
+	if idArg != "" {
+		description += fmt.Sprintf(" The name of the resource to create is also accepted and supplies %s.", idArg)
+	}
+	prop, _ := schema.Properties["name"].(map[string]any)
+	if prop == nil {
+		prop = map[string]any{"type": "string", "description": description}
+	} else {
+		prop = maps.Clone(prop)
+		prop["description"] = fmt.Sprintf("%s %s", prop["description"], description)
+	}
+	schema.Properties["name"] = prop
+	schema.Required = slices.DeleteFunc(slices.Clone(schema.Required), func(r string) bool { return pattern[r] != "" })
+
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		values, ok := request.Params.Arguments.(map[string]any)
+		if !ok {
+			return handler(ctx, request)
+		}
+		raw, _ := values["name"].(string)
+		if raw == "" {
+			return handler(ctx, request)
+		}
+		name, err := names.Parse(raw)
+		if err != nil {
+			return rejected(ctx, "INVALID_ARGUMENT", err.Error()), nil
+		}
+
+		values = maps.Clone(values)
+		path := name
+		switch {
+		case name.Collection() == leaf:
+		case idArg != "" && name.Parent().Collection() == leaf && idArgs[name.Collection()] == idArg:
+			if name.Revision != "" {
+				return rejected(ctx, "INVALID_ARGUMENT", fmt.Sprintf("name %q: a new resource cannot have a revision", raw)), nil
+			}
+			if err := setArg(values, idArg, name.ID(name.Collection())); err != nil {
+				return rejected(ctx, "INVALID_ARGUMENT", err.Error()), nil
+			}
+			path = name.Parent()
+		default:
+			return rejected(ctx, "INVALID_ARGUMENT", fmt.Sprintf("name %q does not match %s", raw, strings.Join(example, "/"))), nil
+		}
+		if path.Collection() == name.Collection() && idArg != "" {
+			// The name of the parent is not a body field of the new resource.
+			delete(values, "name")
+		}
+		for i, s := range path.Segments {
+			id := s.ID
+			if i == len(path.Segments)-1 && path.Revision != "" {
+				id += "@" + path.Revision
+			}
+			arg := args[i]
+			if err := setArg(values, arg, id); err != nil {
+				return rejected(ctx, "INVALID_ARGUMENT", err.Error()), nil
+			}
+		}
+		request.Params.Arguments = values
+		return handler(ctx, request)
+	}
+	return tool
+}
+
+// setArg sets values[arg] unless it already holds a different value.
+func setArg(values map[string]any, arg, value string) error {
+	if v, _ := values[arg].(string); v != "" && v != value {
+		return fmt.Errorf("argument %s %q conflicts with %q from name", arg, v, value)
+	}
+	values[arg] = value
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/config"
+)
+
+// sessionConfigs remembers the configuration each HTTP session was
+// initialised with, so that later requests in the session may omit the
+// configuration headers.
+type sessionConfigs struct {
+	m sync.Map // session ID -> *config.APIConfig
+}
+
+// register binds sessions to the configuration of the request that opened
+// them and forgets it when they close.
+func (s *sessionConfigs) register(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		if cfg := config.FromContext(ctx, nil); cfg != nil {
+			s.m.Store(session.SessionID(), cfg)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.m.Delete(session.SessionID())
+	})
+}
+
+// get returns the configuration bound to the session, or nil.
+func (s *sessionConfigs) get(sessionID string) *config.APIConfig {
+	if sessionID == "" {
+		return nil
+	}
+	if cfg, ok := s.m.Load(sessionID); ok {
+		return cfg.(*config.APIConfig)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sessionServer serves the HTTP mode handler with sessions closed after
+// idle, in front of a fresh fake registry whose URL it also returns.
+func sessionServer(t *testing.T, idle time.Duration) (mcpURL, registryURL string) {
+	t.Helper()
+	up := &upstream{}
+	up.reset()
+	registry := httptest.NewServer(up)
+	t.Cleanup(registry.Close)
+
+	cfg := testConfig()
+	cfg.SessionIdleTimeout = idle
+	mux, streamable := newHTTPHandler(cfg, "HTTP")
+	srv := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		streamable.Shutdown(context.Background())
+		srv.Close()
+	})
+	return srv.URL + "/mcp", registry.URL
+}
+
+// post sends a JSON-RPC message with header and returns the response status,
+// session ID and body.
+func post(t *testing.T, url string, header map[string]string, message string) (int, string, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, resp.Header.Get(server.HeaderKeySessionID), string(body)
+}
+
+// openSession initialises a session with the registry URL in the headers
+// and returns its ID.
+func openSession(t *testing.T, mcpURL, registryURL string) string {
+	t.Helper()
+	status, session, body := post(t, mcpURL, map[string]string{"API_BASE_URL": registryURL},
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "`+mcp.LATEST_PROTOCOL_VERSION+`", "capabilities": {}, "clientInfo": {"name": "sessions", "version": "1"}}}`)
+	if status != http.StatusOK || session == "" {
+		t.Fatalf("initialize = %d with session %q: %s", status, session, body)
+	}
+	inSession := map[string]string{server.HeaderKeySessionID: session}
+	if status, _, body := post(t, mcpURL, inSession, `{"jsonrpc": "2.0", "method": "notifications/initialized"}`); status != http.StatusAccepted {
+		t.Fatalf("notifications/initialized = %d: %s", status, body)
+	}
+	return session
+}
+
+const getPetstore = `{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "get_api", "arguments": {"project": "demo", "location": "global", "api": "petstore"}}}`
+
+func TestSessionBinding(t *testing.T) {
+	mcpURL, registryURL := sessionServer(t, 0)
+	session := openSession(t, mcpURL, registryURL)
+
+	// The registry comes from the session, not the request.
+	status, _, body := post(t, mcpURL, map[string]string{server.HeaderKeySessionID: session}, getPetstore)
+	if status != http.StatusOK || !strings.Contains(body, "Swagger Petstore") || strings.Contains(body, `"isError":true`) {
+		t.Errorf("tools/call without headers = %d: %s", status, body)
+	}
+
+	// Without a session the registry is required.
+	if status, _, body := post(t, mcpURL, nil, getPetstore); status != http.StatusBadRequest {
+		t.Errorf("tools/call without session or headers = %d, want 400: %s", status, body)
+	}
+}
+
+func TestSessionNotFound(t *testing.T) {
+	mcpURL, registryURL := sessionServer(t, 0)
+	openSession(t, mcpURL, registryURL)
+
+	status, _, body := post(t, mcpURL, map[string]string{server.HeaderKeySessionID: "mcp-session-unknown"}, getPetstore)
+	if status != http.StatusNotFound {
+		t.Errorf("tools/call in an unknown session = %d, want 404: %s", status, body)
+	}
+}
+
+func TestSessionExpired(t *testing.T) {
+	mcpURL, registryURL := sessionServer(t, 100*time.Millisecond)
+	inSession := map[string]string{server.HeaderKeySessionID: openSession(t, mcpURL, registryURL)}
+
+	// Idle sessions are swept every second.
+	time.Sleep(2 * time.Second)
+	if status, _, body := post(t, mcpURL, inSession, getPetstore); status != http.StatusNotFound {
+		t.Errorf("tools/call in an expired session = %d, want 404: %s", status, body)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSubscribe(t *testing.T) {
+	up := &upstream{}
+	up.reset()
+	srv := httptest.NewServer(up)
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.BaseURL = srv.URL
+	cfg.AllowedProjects = []string{"demo"}
+	c := serveStdio(t, cfg)
+
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr string
+	}{
+		{"spec", "registry://" + demoSpecName, ""},
+		{"deployment", "registry://projects/demo/locations/global/apis/petstore/deployments/prod", ""},
+		{"artifact", "registry://projects/demo/locations/global/artifacts/owner", "only API spec and deployment resources"},
+		{"other scheme", "https://" + demoSpecName, "must start with projects/"},
+		{"malformed", "registry://projects/demo/apis", "invalid"},
+		{"disallowed project", "registry://projects/other/locations/global/apis/petstore/versions/v1/specs/openapi", "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.SubscribeRequest{}
+			req.Params.URI = tt.uri
+			err := c.Subscribe(context.Background(), req)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Subscribe(%s): %v", tt.uri, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Subscribe(%s) error = %v, want one mentioning %q", tt.uri, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscriptionNotifications(t *testing.T) {
+	up := &upstream{}
+	up.reset()
+	srv := httptest.NewServer(up)
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.BaseURL = srv.URL
+	cfg.WatchInterval = 20 * time.Millisecond
+	c := serveStdio(t, cfg)
+	updates := make(chan string, 10)
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		if n.Method == string(mcp.MethodNotificationResourceUpdated) {
+			uri, _ := n.Params.AdditionalFields["uri"].(string)
+			updates <- uri
+		}
+	})
+
+	spec := "registry://" + demoSpecName
+	deployment := "registry://projects/demo/locations/global/apis/petstore/deployments/prod"
+	for _, uri := range []string{spec, deployment} {
+		req := mcp.SubscribeRequest{}
+		req.Params.URI = uri
+		if err := c.Subscribe(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	quiet := func(after string) {
+		t.Helper()
+		select {
+		case uri := <-updates:
+			t.Errorf("notified of %s %s", uri, after)
+		case <-time.After(10 * cfg.WatchInterval):
+		}
+	}
+	notified := func(want string) {
+		t.Helper()
+		select {
+		case uri := <-updates:
+			if uri != want {
+				t.Errorf("notified of %s, want %s", uri, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no notification of %s", want)
+		}
+	}
+	quiet("while nothing changed")
+
+	if res := callTool(t, c, "update_spec", demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "contents": b64("openapi: 3.1.0")}), nil); res.IsError {
+		t.Fatalf("update_spec failed: %s", text(res))
+	}
+	notified(spec)
+	quiet("after a single change")
+
+	if res := callTool(t, c, "rollback_deployment", demo(map[string]any{"api": "petstore", "deployment": "prod", "revisionId": "00000003"}), nil); res.IsError {
+		t.Fatalf("rollback_deployment failed: %s", text(res))
+	}
+	notified(deployment)
+
+	req := mcp.UnsubscribeRequest{}
+	req.Params.URI = spec
+	if err := c.Unsubscribe(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if res := callTool(t, c, "update_spec", demo(map[string]any{"api": "petstore", "version": "v1", "spec": "openapi", "contents": b64("openapi: 3.1.1")}), nil); res.IsError {
+		t.Fatalf("update_spec failed: %s", text(res))
+	}
+	quiet("after unsubscribing")
+}
@@ -0,0 +1,108 @@
+// Package telemetry sets up OpenTelemetry tracing. Tool calls and the
+// registry requests they send are traced as spans, and the W3C trace context
+// is taken from clients and passed on to the registry.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is the service.name of exported spans unless OTEL_SERVICE_NAME
+// or OTEL_RESOURCE_ATTRIBUTES sets another.
+const ServiceName = "registry-mcp-server"
+
+// Propagator reads and writes the W3C traceparent, tracestate and baggage
+// headers.
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// Tracer returns the tracer of the named instrumentation scope from the
+// global tracer provider, which does nothing until Setup installs one.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// Setup installs a tracer provider exporting spans over OTLP, configured by
+// the standard environment variables:
+//   - OTEL_TRACES_EXPORTER: "otlp" or "none"; when unset, spans are exported
+//     only if an OTLP endpoint is set
+//   - OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_TRACES_ENDPOINT and the
+//     other OTEL_EXPORTER_OTLP_* settings of the exporter
+//   - OTEL_EXPORTER_OTLP_PROTOCOL or OTEL_EXPORTER_OTLP_TRACES_PROTOCOL:
+//     "http/protobuf" (the default) or "grpc"
+//   - OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES, OTEL_TRACES_SAMPLER and
+//     OTEL_BSP_*, as read by the SDK
+//   - OTEL_SDK_DISABLED
+//
+// The returned function flushes pending spans and stops the provider.
+func Setup(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	exporter, err := newExporter(ctx)
+	if err != nil || exporter == nil {
+		return func(context.Context) error { return nil }, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating trace resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// newExporter returns the span exporter selected by the environment, or nil
+// if spans are not exported.
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	if v := os.Getenv("OTEL_SDK_DISABLED"); v != "" {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTEL_SDK_DISABLED %q: %w", v, err)
+		}
+		if disabled {
+			return nil, nil
+		}
+	}
+	switch v := os.Getenv("OTEL_TRACES_EXPORTER"); v {
+	case "none":
+		return nil, nil
+	case "":
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+			return nil, nil
+		}
+	case "otlp":
+	default:
+		return nil, fmt.Errorf("invalid OTEL_TRACES_EXPORTER %q: must be %q or %q", v, "otlp", "none")
+	}
+
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	switch protocol {
+	case "", "http/protobuf":
+		return otlptracehttp.New(ctx)
+	case "grpc":
+		return otlptracegrpc.New(ctx)
+	}
+	return nil, fmt.Errorf("invalid OTLP protocol %q: must be %q or %q", protocol, "http/protobuf", "grpc")
+}
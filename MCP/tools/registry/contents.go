@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/resources"
+)
+
+// contentsMetadata is returned as structured content next to spec and
+// artifact contents.
+type contentsMetadata struct {
+	Name      string `json:"name"`
+	MimeType  string `json:"mimeType"`
+	SizeBytes int    `json:"sizeBytes"`
+	Gzipped   bool   `json:"gzipped,omitempty"`
+	Binary    bool   `json:"binary,omitempty"`
+}
+
+// contentsResult renders the payload of a getContents call. Gzipped payloads
+// are decompressed; textual formats are returned as text and anything else as
+// a base64 blob resource. Both are preceded by a line giving the size and
+// media type.
+func contentsResult(name string, body *client.HttpBody) (*mcp.CallToolResult, error) {
+	data, mimeType, err := body.Uncompressed()
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to read contents", err), nil
+	}
+	meta := contentsMetadata{
+		Name:      name,
+		MimeType:  mimeType,
+		SizeBytes: len(data),
+		Gzipped:   body.Gzipped(),
+		Binary:    !client.IsText(mimeType, data),
+	}
+
+	summary := fmt.Sprintf("%s: %d bytes of %s", name, meta.SizeBytes, mimeType)
+	if meta.Gzipped {
+		summary += fmt.Sprintf(" (decompressed from %d bytes)", len(body.Data))
+	}
+
+	var payload mcp.Content
+	if meta.Binary {
+		payload = mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      resources.URI(name),
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString(data),
+		})
+	} else {
+		payload = mcp.NewTextContent(string(data))
+	}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{mcp.NewTextContent(summary), payload},
+		StructuredContent: meta,
+	}, nil
+}
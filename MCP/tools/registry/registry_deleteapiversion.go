@@ -2,18 +2,15 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"strings"
 
+	"github.com/registry-api/mcp-server/client"
 	"github.com/registry-api/mcp-server/config"
 	"github.com/registry-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Registry_deleteapiversionHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c := client.New(cfg)
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
@@ -51,56 +48,17 @@ func Registry_deleteapiversionHandler(cfg *config.APIConfig) func(ctx context.Co
 		if !ok {
 			return mcp.NewToolResultError("Invalid path parameter: version"), nil
 		}
-		queryParams := make([]string, 0)
-		if val, ok := args["force"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("force=%v", val))
+		force, _ := args["force"].(bool)
+		if err := c.DeleteApiVersion(ctx, project, location, api, version, force); err != nil {
+			return errorResult(err), nil
 		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/apis/%s/versions/%s%s", cfg.BaseURL, project, location, api, version, queryString)
-		req, err := http.NewRequest("DELETE", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// No authentication required for this endpoint
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.Status
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
+		return mcp.NewToolResultText("{}"), nil
 	}
 }
 
 func CreateRegistry_deleteapiversionTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("delete_v1_projects_project_locations_location_apis_api_versions_version",
-		mcp.WithDescription("DeleteApiVersion removes a specified version and all of the resources that
- it owns."),
+		mcp.WithDescription("DeleteApiVersion removes a specified version and all of the resources that it owns."),
 		mcp.WithString("project", mcp.Required(), mcp.Description("The project id.")),
 		mcp.WithString("location", mcp.Required(), mcp.Description("The location id.")),
 		mcp.WithString("api", mcp.Required(), mcp.Description("The api id.")),
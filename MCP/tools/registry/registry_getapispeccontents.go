@@ -2,17 +2,16 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/registry-api/mcp-server/client"
 	"github.com/registry-api/mcp-server/config"
 	"github.com/registry-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Registry_getapispeccontentsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c := client.New(cfg)
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
@@ -58,50 +57,18 @@ func Registry_getapispeccontentsHandler(cfg *config.APIConfig) func(ctx context.
 		if !ok {
 			return mcp.NewToolResultError("Invalid path parameter: spec"), nil
 		}
-		url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/apis/%s/versions/%s/specs/%s:getContents", cfg.BaseURL, project, location, api, version, spec)
-		req, err := http.NewRequest("GET", url, nil)
+		result, err := c.GetApiSpecContents(ctx, project, location, api, version, spec)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
+			return errorResult(err), nil
 		}
-		// No authentication required for this endpoint
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.Status
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
+		name := fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/specs/%s", project, location, api, version, spec)
+		return contentsResult(name, result)
 	}
 }
 
 func CreateRegistry_getapispeccontentsTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_v1_projects_project_locations_location_apis_api_versions_version_specs_spec:getContents",
-		mcp.WithDescription("GetApiSpecContents returns the contents of a specified spec.
- If specs are stored with GZip compression, the default behavior
- is to return the spec uncompressed (the mime_type response field
- indicates the exact format returned)."),
+		mcp.WithDescription("GetApiSpecContents returns the contents of a specified spec. If specs are stored with GZip compression, the default behavior is to return the spec uncompressed (the mime_type response field indicates the exact format returned)."),
 		mcp.WithString("project", mcp.Required(), mcp.Description("The project id.")),
 		mcp.WithString("location", mcp.Required(), mcp.Description("The location id.")),
 		mcp.WithString("api", mcp.Required(), mcp.Description("The api id.")),
@@ -2,18 +2,15 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"strings"
 
+	"github.com/registry-api/mcp-server/client"
 	"github.com/registry-api/mcp-server/config"
 	"github.com/registry-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Registry_listapideploymentsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c := client.New(cfg)
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
@@ -43,55 +40,34 @@ func Registry_listapideploymentsHandler(cfg *config.APIConfig) func(ctx context.
 		if !ok {
 			return mcp.NewToolResultError("Invalid path parameter: api"), nil
 		}
-		queryParams := make([]string, 0)
-		if val, ok := args["pageSize"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("pageSize=%v", val))
-		}
-		if val, ok := args["pageToken"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("pageToken=%v", val))
-		}
-		if val, ok := args["filter"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("filter=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/apis/%s/deployments%s", cfg.BaseURL, project, location, api, queryString)
-		req, err := http.NewRequest("GET", url, nil)
+		opts := &client.ListOptions{}
+		if val, ok := args["pageSize"].(float64); ok {
+			opts.PageSize = int(val)
+		}
+		if val, ok := args["pageToken"].(string); ok {
+			opts.PageToken = val
+		}
+		if val, ok := args["filter"].(string); ok {
+			opts.Filter = val
+		}
+		if maxItems, all := paginationArgs(args); all {
+			deployments, next, err := client.CollectPages(ctx, opts, maxItems, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiDeployment, string, error) {
+				page, err := c.ListApiDeployments(ctx, project, location, api, opts)
+				if err != nil {
+					return nil, "", err
+				}
+				return page.Apideployments, page.Nextpagetoken, nil
+			})
+			if err != nil {
+				return errorResult(err), nil
+			}
+			return pagedResult(&models.ListApiDeploymentsResponse{Apideployments: deployments, Nextpagetoken: next}, len(deployments), next)
+		}
+		result, err := c.ListApiDeployments(ctx, project, location, api, opts)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
+			return errorResult(err), nil
 		}
-		// No authentication required for this endpoint
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.ListApiDeploymentsResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
+		return jsonResult(result)
 	}
 }
 
@@ -104,6 +80,8 @@ func CreateRegistry_listapideploymentsTool(cfg *config.APIConfig) models.Tool {
 		mcp.WithNumber("pageSize", mcp.Description("The maximum number of deployments to return. The service may return fewer than this value. If unspecified, at most 50 values will be returned. The maximum is 1000; values above 1000 will be coerced to 1000.")),
 		mcp.WithString("pageToken", mcp.Description("A page token, received from a previous `ListApiDeployments` call. Provide this to retrieve the subsequent page. When paginating, all other parameters provided to `ListApiDeployments` must match the call that provided the page token.")),
 		mcp.WithString("filter", mcp.Description("An expression that can be used to filter the list. Filters use the Common Expression Language and can refer to all message fields.")),
+		mcp.WithBoolean("all", mcp.Description("If true, follow nextPageToken and return all matching deployments in one merged list (up to maxItems, default 1000).")),
+		mcp.WithNumber("maxItems", mcp.Description("The maximum number of deployments to return across pages. Implies all. If more exist, the result ends with a notice and the nextPageToken to resume from.")),
 	)
 
 	return models.Tool{
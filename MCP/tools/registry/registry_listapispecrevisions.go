@@ -2,18 +2,15 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"strings"
 
+	"github.com/registry-api/mcp-server/client"
 	"github.com/registry-api/mcp-server/config"
 	"github.com/registry-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Registry_listapispecrevisionsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c := client.New(cfg)
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
@@ -59,59 +56,37 @@ func Registry_listapispecrevisionsHandler(cfg *config.APIConfig) func(ctx contex
 		if !ok {
 			return mcp.NewToolResultError("Invalid path parameter: spec"), nil
 		}
-		queryParams := make([]string, 0)
-		if val, ok := args["pageSize"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("pageSize=%v", val))
-		}
-		if val, ok := args["pageToken"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("pageToken=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/apis/%s/versions/%s/specs/%s:listRevisions%s", cfg.BaseURL, project, location, api, version, spec, queryString)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// No authentication required for this endpoint
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.ListApiSpecRevisionsResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
+		opts := &client.ListOptions{}
+		if val, ok := args["pageSize"].(float64); ok {
+			opts.PageSize = int(val)
+		}
+		if val, ok := args["pageToken"].(string); ok {
+			opts.PageToken = val
+		}
+		if maxItems, all := paginationArgs(args); all {
+			revisions, next, err := client.CollectPages(ctx, opts, maxItems, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiSpec, string, error) {
+				page, err := c.ListApiSpecRevisions(ctx, project, location, api, version, spec, opts)
+				if err != nil {
+					return nil, "", err
+				}
+				return page.Apispecs, page.Nextpagetoken, nil
+			})
+			if err != nil {
+				return errorResult(err), nil
+			}
+			return pagedResult(&models.ListApiSpecRevisionsResponse{Apispecs: revisions, Nextpagetoken: next}, len(revisions), next)
+		}
+		result, err := c.ListApiSpecRevisions(ctx, project, location, api, version, spec, opts)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
+			return errorResult(err), nil
 		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
+		return jsonResult(result)
 	}
 }
 
 func CreateRegistry_listapispecrevisionsTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_v1_projects_project_locations_location_apis_api_versions_version_specs_spec:listRevisions",
-		mcp.WithDescription("ListApiSpecRevisions lists all revisions of a spec.
- Revisions are returned in descending order of revision creation time."),
+		mcp.WithDescription("ListApiSpecRevisions lists all revisions of a spec. Revisions are returned in descending order of revision creation time."),
 		mcp.WithString("project", mcp.Required(), mcp.Description("The project id.")),
 		mcp.WithString("location", mcp.Required(), mcp.Description("The location id.")),
 		mcp.WithString("api", mcp.Required(), mcp.Description("The api id.")),
@@ -119,6 +94,8 @@ func CreateRegistry_listapispecrevisionsTool(cfg *config.APIConfig) models.Tool
 		mcp.WithString("spec", mcp.Required(), mcp.Description("The spec id.")),
 		mcp.WithNumber("pageSize", mcp.Description("The maximum number of revisions to return per page.")),
 		mcp.WithString("pageToken", mcp.Description("The page token, received from a previous ListApiSpecRevisions call. Provide this to retrieve the subsequent page.")),
+		mcp.WithBoolean("all", mcp.Description("If true, follow nextPageToken and return all matching revisions in one merged list (up to maxItems, default 1000).")),
+		mcp.WithNumber("maxItems", mcp.Description("The maximum number of revisions to return across pages. Implies all. If more exist, the result ends with a notice and the nextPageToken to resume from.")),
 	)
 
 	return models.Tool{
@@ -2,18 +2,15 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"strings"
 
+	"github.com/registry-api/mcp-server/client"
 	"github.com/registry-api/mcp-server/config"
 	"github.com/registry-api/mcp-server/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Registry_listapispecsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c := client.New(cfg)
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
@@ -51,55 +48,34 @@ func Registry_listapispecsHandler(cfg *config.APIConfig) func(ctx context.Contex
 		if !ok {
 			return mcp.NewToolResultError("Invalid path parameter: version"), nil
 		}
-		queryParams := make([]string, 0)
-		if val, ok := args["pageSize"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("pageSize=%v", val))
-		}
-		if val, ok := args["pageToken"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("pageToken=%v", val))
-		}
-		if val, ok := args["filter"]; ok {
-			queryParams = append(queryParams, fmt.Sprintf("filter=%v", val))
-		}
-		queryString := ""
-		if len(queryParams) > 0 {
-			queryString = "?" + strings.Join(queryParams, "&")
-		}
-		url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/apis/%s/versions/%s/specs%s", cfg.BaseURL, project, location, api, version, queryString)
-		req, err := http.NewRequest("GET", url, nil)
+		opts := &client.ListOptions{}
+		if val, ok := args["pageSize"].(float64); ok {
+			opts.PageSize = int(val)
+		}
+		if val, ok := args["pageToken"].(string); ok {
+			opts.PageToken = val
+		}
+		if val, ok := args["filter"].(string); ok {
+			opts.Filter = val
+		}
+		if maxItems, all := paginationArgs(args); all {
+			specs, next, err := client.CollectPages(ctx, opts, maxItems, func(ctx context.Context, opts *client.ListOptions) ([]models.ApiSpec, string, error) {
+				page, err := c.ListApiSpecs(ctx, project, location, api, version, opts)
+				if err != nil {
+					return nil, "", err
+				}
+				return page.Apispecs, page.Nextpagetoken, nil
+			})
+			if err != nil {
+				return errorResult(err), nil
+			}
+			return pagedResult(&models.ListApiSpecsResponse{Apispecs: specs, Nextpagetoken: next}, len(specs), next)
+		}
+		result, err := c.ListApiSpecs(ctx, project, location, api, version, opts)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
+			return errorResult(err), nil
 		}
-		// No authentication required for this endpoint
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.ListApiSpecsResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
+		return jsonResult(result)
 	}
 }
 
@@ -113,6 +89,8 @@ func CreateRegistry_listapispecsTool(cfg *config.APIConfig) models.Tool {
 		mcp.WithNumber("pageSize", mcp.Description("The maximum number of specs to return. The service may return fewer than this value. If unspecified, at most 50 values will be returned. The maximum is 1000; values above 1000 will be coerced to 1000.")),
 		mcp.WithString("pageToken", mcp.Description("A page token, received from a previous `ListApiSpecs` call. Provide this to retrieve the subsequent page. When paginating, all other parameters provided to `ListApiSpecs` must match the call that provided the page token.")),
 		mcp.WithString("filter", mcp.Description("An expression that can be used to filter the list. Filters use the Common Expression Language and can refer to all message fields except contents.")),
+		mcp.WithBoolean("all", mcp.Description("If true, follow nextPageToken and return all matching specs in one merged list (up to maxItems, default 1000).")),
+		mcp.WithNumber("maxItems", mcp.Description("The maximum number of specs to return across pages. Implies all. If more exist, the result ends with a notice and the nextPageToken to resume from.")),
 	)
 
 	return models.Tool{
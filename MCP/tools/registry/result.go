@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/names"
+)
+
+// jsonResult renders a decoded registry response as indented JSON.
+func jsonResult(result any) (*mcp.CallToolResult, error) {
+	prettyJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
+	}
+	return mcp.NewToolResultText(string(prettyJSON)), nil
+}
+
+// errorResult converts an error returned by the registry client into a tool
+// error result. Registry errors carry the decoded google.rpc.Status as
+// structured content so callers can branch on its status (NOT_FOUND,
+// PERMISSION_DENIED, ...) rather than on the message text.
+func errorResult(err error) *mcp.CallToolResult {
+	var nameErr *names.Error
+	if errors.As(err, &nameErr) {
+		// Rejected before anything was sent upstream.
+		return mcp.NewToolResultError(err.Error())
+	}
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		return mcp.NewToolResultErrorFromErr("Request failed", err)
+	}
+
+	var text strings.Builder
+	text.WriteString(apiErr.Error())
+	if br := apiErr.Status.BadRequest; br != nil {
+		for _, v := range br.FieldViolations {
+			fmt.Fprintf(&text, "\n- %s: %s", v.Field, v.Description)
+		}
+	}
+	if info := apiErr.Status.ErrorInfo; info != nil && info.Reason != "" {
+		fmt.Fprintf(&text, "\nReason: %s", info.Reason)
+	}
+
+	res := mcp.NewToolResultStructured(apiErr.Status, text.String())
+	res.IsError = true
+	return res
+}
+
+// paginationArgs reads the optional "all" and "maxItems" arguments of the list
+// tools. Setting maxItems implies all.
+func paginationArgs(args map[string]any) (maxItems int, all bool) {
+	if val, ok := args["maxItems"].(float64); ok && val > 0 {
+		return int(val), true
+	}
+	if val, ok := args["all"].(bool); ok && val {
+		return client.DefaultMaxItems, true
+	}
+	return 0, false
+}
+
+// pagedResult renders a listing merged from several pages. If the listing
+// stopped at the item cap, a notice with the token to resume from is added.
+func pagedResult(result any, count int, nextPageToken string) (*mcp.CallToolResult, error) {
+	res, err := jsonResult(result)
+	if err != nil || res.IsError || nextPageToken == "" {
+		return res, err
+	}
+	res.Content = append(res.Content, mcp.NewTextContent(fmt.Sprintf(
+		"Result truncated after %d items; more are available. Call again with pageToken=%q (and maxItems) to continue.",
+		count, nextPageToken)))
+	return res, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/registry-api/mcp-server/client"
+	"github.com/registry-api/mcp-server/models"
+	"github.com/registry-api/mcp-server/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the tool call spans.
+const tracerName = "github.com/registry-api/mcp-server"
+
+// withTracing runs each call of tool in a span, the parent of the spans of
+// the registry requests it sends. A trace context the client passes in the
+// request's _meta takes precedence over one from the HTTP headers.
+func withTracing(tool models.Tool) models.Tool {
+	name := tool.Definition.Name
+	handler := tool.Handler
+	tool.Handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if meta := request.Params.Meta; meta != nil {
+			ctx = telemetry.Propagator.Extract(ctx, metaCarrier(meta.AdditionalFields))
+		}
+		attrs := []attribute.KeyValue{
+			attribute.String("mcp.method.name", string(mcp.MethodToolsCall)),
+			attribute.String("gen_ai.tool.name", name),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			attrs = append(attrs, attribute.String("mcp.session.id", session.SessionID()))
+		}
+		ctx, span := telemetry.Tracer(tracerName).Start(ctx, string(mcp.MethodToolsCall)+" "+name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		ctx, calls := client.WithTrace(ctx)
+		ctx, f := withFailure(ctx)
+		result, err := handler(ctx, request)
+		if err != nil || (result != nil && result.IsError) {
+			span.SetAttributes(attribute.String("error.type", errorCode(calls, f, err)))
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			} else {
+				span.SetStatus(codes.Error, resultText(result))
+			}
+		}
+		return result, err
+	}
+	return tool
+}
+
+// metaCarrier reads the string fields of a request's _meta, such as
+// "traceparent", as propagation headers.
+type metaCarrier map[string]any
+
+func (m metaCarrier) Get(key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func (m metaCarrier) Set(key, value string) {
+	m[key] = value
+}
+
+func (m metaCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+var _ propagation.TextMapCarrier = metaCarrier(nil)
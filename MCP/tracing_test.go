@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/registry-api/mcp-server/fake"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The tracing tests record spans in memory and check that a tool call's span
+// continues the client's trace and parents one span per registry request,
+// whose context reaches the registry.
+
+const (
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerSpanID  = "00f067aa0ba902b7"
+	traceparent   = "00-" + callerTraceID + "-" + callerSpanID + "-01"
+)
+
+// recordSpans installs a tracer provider exporting to memory for the rest of
+// the test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+		tp.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// flaky answers its first request with 503 and passes the others to the
+// registry, recording the traceparent header of each.
+type flaky struct {
+	registry http.Handler
+
+	mu           sync.Mutex
+	traceparents []string
+}
+
+func (f *flaky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.traceparents = append(f.traceparents, r.Header.Get("traceparent"))
+	first := len(f.traceparents) == 1
+	f.mu.Unlock()
+	if first {
+		http.Error(w, `{"error":{"code":503,"message":"try again","status":"UNAVAILABLE"}}`, http.StatusServiceUnavailable)
+		return
+	}
+	f.registry.ServeHTTP(w, r)
+}
+
+func TestTracingHTTP(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	exporter := recordSpans(t)
+
+	f := fake.New()
+	f.Seed("demo", "global")
+	up := &flaky{registry: f}
+	registry := httptest.NewServer(up)
+	defer registry.Close()
+
+	cfg := testConfig()
+	cfg.Retry.MaxAttempts = 2
+	cfg.Retry.InitialBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = time.Millisecond
+	mux, streamable := newHTTPHandler(cfg, "HTTP")
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	defer streamable.Shutdown(context.Background())
+	tr, err := transport.NewStreamableHTTP(srv.URL+"/mcp", transport.WithHTTPHeaders(map[string]string{
+		"API_BASE_URL": registry.URL,
+		"traceparent":  traceparent,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewClient(tr)
+	defer c.Close()
+	initialize(t, c)
+
+	// Two pages, the first of which is retried.
+	callTool(t, c, "list_apis", demo(map[string]any{"pageSize": 1, "all": true}), nil)
+
+	tool := toolSpan(t, exporter.GetSpans(), "list_apis")
+	if got := tool.Parent.SpanID().String(); got != callerSpanID {
+		t.Errorf("tool span parent = %s, want the caller's span %s", got, callerSpanID)
+	}
+	requests := childSpans(exporter.GetSpans(), tool)
+	if len(requests) != 3 {
+		t.Fatalf("got %d registry request spans, want 3 (a retried page and another page)", len(requests))
+	}
+	wantStatus := []int64{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK}
+	wantResends := []int64{0, 1, 0}
+	for i, span := range requests {
+		if span.Name != "GET /v1/projects/*/locations/*/apis" || span.SpanKind != trace.SpanKindClient {
+			t.Errorf("request %d: span %q of kind %v, want a client span GET /v1/projects/*/locations/*/apis", i, span.Name, span.SpanKind)
+		}
+		if got := intAttr(span.Attributes, "http.response.status_code"); got != wantStatus[i] {
+			t.Errorf("request %d: status code %d, want %d", i, got, wantStatus[i])
+		}
+		if got := intAttr(span.Attributes, "http.request.resend_count"); got != wantResends[i] {
+			t.Errorf("request %d: resend count %d, want %d", i, got, wantResends[i])
+		}
+		want := "00-" + callerTraceID + "-" + span.SpanContext.SpanID().String() + "-01"
+		if got := up.traceparents[i]; got != want {
+			t.Errorf("request %d: registry got traceparent %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestTracingMeta(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	exporter := recordSpans(t)
+
+	f := fake.New()
+	f.Seed("demo", "global")
+	registry := httptest.NewServer(f)
+	defer registry.Close()
+	c := connectStdio(t, registry.URL)
+
+	res := callTool(t, c, "get_api", demo(map[string]any{"api": "missing"}), &mcp.Meta{
+		AdditionalFields: map[string]any{"traceparent": traceparent},
+	})
+	if !res.IsError {
+		t.Fatal("get_api of a missing API succeeded")
+	}
+
+	tool := toolSpan(t, exporter.GetSpans(), "get_api")
+	if got := tool.Parent.SpanID().String(); got != callerSpanID {
+		t.Errorf("tool span parent = %s, want the caller's span %s", got, callerSpanID)
+	}
+	if got := stringAttr(tool.Attributes, "error.type"); got != "NOT_FOUND" {
+		t.Errorf("tool span error.type = %q, want NOT_FOUND", got)
+	}
+	if requests := childSpans(exporter.GetSpans(), tool); len(requests) != 1 {
+		t.Errorf("got %d registry request spans, want 1", len(requests))
+	}
+	if got := f.Requests()[0].Header.Get("traceparent"); got == "" || got[3:35] != callerTraceID {
+		t.Errorf("registry got traceparent %q, want one in trace %s", got, callerTraceID)
+	}
+}
+
+func callTool(t *testing.T, c *client.Client, name string, args map[string]any, meta *mcp.Meta) *mcp.CallToolResult {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	req.Params.Meta = meta
+	res, err := c.CallTool(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// toolSpan returns the span of the call of tool, which must continue the
+// caller's trace.
+func toolSpan(t *testing.T, spans tracetest.SpanStubs, tool string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == "tools/call "+tool {
+			if got := span.SpanContext.TraceID().String(); got != callerTraceID {
+				t.Errorf("tool span in trace %s, want the caller's trace %s", got, callerTraceID)
+			}
+			if got := stringAttr(span.Attributes, "gen_ai.tool.name"); got != tool {
+				t.Errorf("tool span gen_ai.tool.name = %q, want %q", got, tool)
+			}
+			return span
+		}
+	}
+	t.Fatalf("no span for the call of %s among %d spans", tool, len(spans))
+	return tracetest.SpanStub{}
+}
+
+// childSpans returns the spans whose parent is parent, oldest first.
+func childSpans(spans tracetest.SpanStubs, parent tracetest.SpanStub) []tracetest.SpanStub {
+	var out []tracetest.SpanStub
+	for _, span := range spans {
+		if span.Parent.SpanID() == parent.SpanContext.SpanID() {
+			out = append(out, span)
+		}
+	}
+	return out
+}
+
+func intAttr(attrs []attribute.KeyValue, key string) int64 {
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			return kv.Value.AsInt64()
+		}
+	}
+	return 0
+}
+
+func stringAttr(attrs []attribute.KeyValue, key string) string {
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}